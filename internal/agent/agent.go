@@ -0,0 +1,238 @@
+package agent
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Label is the launchd job label for the background recorder.
+const Label = "com.lu-zhengda.macctl.recorder"
+
+// Config holds the settings written into the launch agent plist.
+type Config struct {
+	Label      string
+	Executable string
+	Interval   string
+	Domains    []string
+	StdoutPath string
+	StderrPath string
+}
+
+// Status holds the launch agent state as reported by launchctl.
+type Status struct {
+	Installed    bool      `json:"installed"`
+	Loaded       bool      `json:"loaded"`
+	PlistPath    string    `json:"plist_path"`
+	State        string    `json:"state,omitempty"`
+	PID          int       `json:"pid,omitempty"`
+	Runs         int       `json:"runs,omitempty"`
+	LastExitCode *int      `json:"last_exit_code,omitempty"`
+	LastRun      time.Time `json:"last_run,omitzero"`
+	StdoutPath   string    `json:"stdout_path,omitempty"`
+	StderrPath   string    `json:"stderr_path,omitempty"`
+}
+
+// DefaultConfig returns a Config for the current executable with logs under
+// ~/Library/Logs/macctl.
+func DefaultConfig(interval string, domains []string) (*Config, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return nil, fmt.Errorf("failed to locate macctl executable: %w", err)
+	}
+	if resolved, err := filepath.EvalSymlinks(exe); err == nil {
+		exe = resolved
+	}
+
+	logDir, err := logDir()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Config{
+		Label:      Label,
+		Executable: exe,
+		Interval:   interval,
+		Domains:    domains,
+		StdoutPath: filepath.Join(logDir, "recorder.log"),
+		StderrPath: filepath.Join(logDir, "recorder.err.log"),
+	}, nil
+}
+
+// GeneratePlist renders the launch agent property list for cfg.
+func GeneratePlist(cfg Config) string {
+	args := []string{cfg.Executable, "record", "--every", cfg.Interval}
+	if len(cfg.Domains) > 0 {
+		args = append(args, "--domains", strings.Join(cfg.Domains, ","))
+	}
+
+	var b strings.Builder
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8"?>` + "\n")
+	b.WriteString(`<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">` + "\n")
+	b.WriteString(`<plist version="1.0">` + "\n")
+	b.WriteString("<dict>\n")
+	writeKeyString(&b, "Label", cfg.Label)
+	b.WriteString("\t<key>ProgramArguments</key>\n")
+	b.WriteString("\t<array>\n")
+	for _, a := range args {
+		fmt.Fprintf(&b, "\t\t<string>%s</string>\n", escape(a))
+	}
+	b.WriteString("\t</array>\n")
+	b.WriteString("\t<key>RunAtLoad</key>\n\t<true/>\n")
+	b.WriteString("\t<key>KeepAlive</key>\n\t<true/>\n")
+	b.WriteString("\t<key>ProcessType</key>\n\t<string>Background</string>\n")
+	writeKeyString(&b, "StandardOutPath", cfg.StdoutPath)
+	writeKeyString(&b, "StandardErrorPath", cfg.StderrPath)
+	b.WriteString("</dict>\n")
+	b.WriteString("</plist>\n")
+	return b.String()
+}
+
+// PlistPath returns the path of the launch agent plist.
+func PlistPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(home, "Library", "LaunchAgents", Label+".plist"), nil
+}
+
+// Install writes the launch agent plist and loads it with launchctl,
+// replacing any previously loaded copy.
+func Install(cfg Config) (string, error) {
+	path, err := PlistPath()
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return "", fmt.Errorf("failed to create LaunchAgents directory: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(cfg.StdoutPath), 0o755); err != nil {
+		return "", fmt.Errorf("failed to create log directory: %w", err)
+	}
+
+	if err := os.WriteFile(path, []byte(GeneratePlist(cfg)), 0o644); err != nil {
+		return "", fmt.Errorf("failed to write launch agent plist: %w", err)
+	}
+
+	// Unload any existing copy first; it's fine if none is loaded.
+	exec.Command("launchctl", "bootout", serviceTarget()).Run()
+
+	if out, err := exec.Command("launchctl", "bootstrap", domainTarget(), path).CombinedOutput(); err != nil {
+		return "", fmt.Errorf("failed to load launch agent: %s: %w", strings.TrimSpace(string(out)), err)
+	}
+
+	return path, nil
+}
+
+// Uninstall unloads the launch agent and removes its plist.
+func Uninstall() error {
+	path, err := PlistPath()
+	if err != nil {
+		return err
+	}
+
+	exec.Command("launchctl", "bootout", serviceTarget()).Run()
+
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove launch agent plist: %w", err)
+	}
+	return nil
+}
+
+// GetStatus reports whether the launch agent is installed and loaded, along
+// with its last exit status and the time it last wrote to its log.
+func GetStatus() (*Status, error) {
+	path, err := PlistPath()
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Status{PlistPath: path}
+	if _, err := os.Stat(path); err == nil {
+		s.Installed = true
+	}
+
+	out, err := exec.Command("launchctl", "print", serviceTarget()).Output()
+	if err == nil {
+		parseLaunchctlPrint(string(out), s)
+		s.Loaded = true
+	}
+
+	for _, p := range []string{s.StdoutPath, s.StderrPath} {
+		if p == "" {
+			continue
+		}
+		if fi, err := os.Stat(p); err == nil && fi.ModTime().After(s.LastRun) {
+			s.LastRun = fi.ModTime()
+		}
+	}
+
+	return s, nil
+}
+
+var (
+	printStateRe    = regexp.MustCompile(`(?m)^\s*state = (.+)$`)
+	printPIDRe      = regexp.MustCompile(`(?m)^\s*pid = (\d+)$`)
+	printRunsRe     = regexp.MustCompile(`(?m)^\s*runs = (\d+)$`)
+	printExitCodeRe = regexp.MustCompile(`(?m)^\s*last exit code = (-?\d+)`)
+	printStdoutRe   = regexp.MustCompile(`(?m)^\s*stdout path = (.+)$`)
+	printStderrRe   = regexp.MustCompile(`(?m)^\s*stderr path = (.+)$`)
+)
+
+func parseLaunchctlPrint(output string, s *Status) {
+	if m := printStateRe.FindStringSubmatch(output); len(m) > 1 {
+		s.State = strings.TrimSpace(m[1])
+	}
+	if m := printPIDRe.FindStringSubmatch(output); len(m) > 1 {
+		s.PID, _ = strconv.Atoi(m[1])
+	}
+	if m := printRunsRe.FindStringSubmatch(output); len(m) > 1 {
+		s.Runs, _ = strconv.Atoi(m[1])
+	}
+	if m := printExitCodeRe.FindStringSubmatch(output); len(m) > 1 {
+		if code, err := strconv.Atoi(m[1]); err == nil {
+			s.LastExitCode = &code
+		}
+	}
+	if m := printStdoutRe.FindStringSubmatch(output); len(m) > 1 {
+		s.StdoutPath = strings.TrimSpace(m[1])
+	}
+	if m := printStderrRe.FindStringSubmatch(output); len(m) > 1 {
+		s.StderrPath = strings.TrimSpace(m[1])
+	}
+}
+
+func logDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(home, "Library", "Logs", "macctl"), nil
+}
+
+func domainTarget() string {
+	return fmt.Sprintf("gui/%d", os.Getuid())
+}
+
+func serviceTarget() string {
+	return domainTarget() + "/" + Label
+}
+
+func writeKeyString(b *strings.Builder, key, val string) {
+	fmt.Fprintf(b, "\t<key>%s</key>\n\t<string>%s</string>\n", escape(key), escape(val))
+}
+
+func escape(s string) string {
+	var buf bytes.Buffer
+	xml.EscapeText(&buf, []byte(s))
+	return buf.String()
+}
@@ -0,0 +1,118 @@
+package agent
+
+import (
+	"encoding/xml"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGeneratePlistGolden(t *testing.T) {
+	cfg := Config{
+		Label:      Label,
+		Executable: "/opt/homebrew/bin/macctl",
+		Interval:   "5m",
+		Domains:    []string{"power", "disk", "thermal"},
+		StdoutPath: "/Users/test/Library/Logs/macctl/recorder.log",
+		StderrPath: "/Users/test/Library/Logs/macctl/recorder.err.log",
+	}
+
+	got := GeneratePlist(cfg)
+
+	want, err := os.ReadFile(filepath.Join("testdata", "recorder.plist"))
+	if err != nil {
+		t.Fatalf("failed to read golden file: %v", err)
+	}
+
+	if got != string(want) {
+		t.Errorf("GeneratePlist mismatch\n--- got ---\n%s\n--- want ---\n%s", got, want)
+	}
+}
+
+func TestGeneratePlistEscapesAndIsWellFormed(t *testing.T) {
+	cfg := Config{
+		Label:      Label,
+		Executable: "/Users/a&b/bin/<macctl>",
+		Interval:   "1h",
+		StdoutPath: "/tmp/out.log",
+		StderrPath: "/tmp/err.log",
+	}
+
+	got := GeneratePlist(cfg)
+
+	if !strings.Contains(got, "/Users/a&amp;b/bin/&lt;macctl&gt;") {
+		t.Errorf("expected escaped executable path, got:\n%s", got)
+	}
+	if strings.Contains(got, "--domains") {
+		t.Error("expected no --domains argument when domains are empty")
+	}
+
+	dec := xml.NewDecoder(strings.NewReader(got))
+	dec.Strict = false
+	for {
+		if _, err := dec.Token(); err != nil {
+			if err == io.EOF {
+				break
+			}
+			t.Fatalf("generated plist is not well-formed XML: %v", err)
+		}
+	}
+}
+
+func TestParseLaunchctlPrint(t *testing.T) {
+	input := `gui/501/com.lu-zhengda.macctl.recorder = {
+	active count = 1
+	path = /Users/test/Library/LaunchAgents/com.lu-zhengda.macctl.recorder.plist
+	type = LaunchAgent
+	state = running
+
+	program = /opt/homebrew/bin/macctl
+	arguments = {
+		/opt/homebrew/bin/macctl
+		record
+	}
+
+	stdout path = /Users/test/Library/Logs/macctl/recorder.log
+	stderr path = /Users/test/Library/Logs/macctl/recorder.err.log
+	runs = 3
+	pid = 4242
+	last exit code = 1
+}
+`
+	s := &Status{}
+	parseLaunchctlPrint(input, s)
+
+	if s.State != "running" {
+		t.Errorf("State = %q, want %q", s.State, "running")
+	}
+	if s.PID != 4242 {
+		t.Errorf("PID = %d, want 4242", s.PID)
+	}
+	if s.Runs != 3 {
+		t.Errorf("Runs = %d, want 3", s.Runs)
+	}
+	if s.LastExitCode == nil || *s.LastExitCode != 1 {
+		t.Errorf("LastExitCode = %v, want 1", s.LastExitCode)
+	}
+	if s.StdoutPath != "/Users/test/Library/Logs/macctl/recorder.log" {
+		t.Errorf("StdoutPath = %q", s.StdoutPath)
+	}
+}
+
+func TestParseLaunchctlPrintNeverExited(t *testing.T) {
+	input := `	state = waiting
+	runs = 0
+	last exit code = (never exited)
+`
+	s := &Status{}
+	parseLaunchctlPrint(input, s)
+
+	if s.State != "waiting" {
+		t.Errorf("State = %q, want %q", s.State, "waiting")
+	}
+	if s.LastExitCode != nil {
+		t.Errorf("LastExitCode = %d, want nil", *s.LastExitCode)
+	}
+}
@@ -0,0 +1,222 @@
+package backup
+
+import (
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lu-zhengda/macctl/internal/plist"
+	"github.com/lu-zhengda/macctl/internal/power"
+)
+
+// DefaultMaxAge is how old the latest backup may be before a check fails.
+const DefaultMaxAge = "48h"
+
+const backupDateLayout = "2006-01-02-150405"
+
+// backupDateRe matches the date in a backup path such as
+// "/Volumes/TM/2026-10-17-093012.backup/2026-10-17-093012.backup".
+var backupDateRe = regexp.MustCompile(`(\d{4}-\d{2}-\d{2}-\d{6})`)
+
+// Status holds the state of the current Time Machine backup, if any.
+type Status struct {
+	Running          bool    `json:"running"`
+	Phase            string  `json:"phase,omitempty"`
+	Percent          float64 `json:"percent"`
+	BytesCopied      int64   `json:"bytes_copied,omitempty"`
+	TotalBytes       int64   `json:"total_bytes,omitempty"`
+	FilesCopied      int64   `json:"files_copied,omitempty"`
+	TotalFiles       int64   `json:"total_files,omitempty"`
+	RemainingSeconds int64   `json:"remaining_seconds,omitempty"`
+	DestinationID    string  `json:"destination_id,omitempty"`
+	DestinationMount string  `json:"destination_mount_point,omitempty"`
+}
+
+// Destination is a configured Time Machine backup destination.
+type Destination struct {
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	Kind       string `json:"kind"`
+	MountPoint string `json:"mount_point,omitempty"`
+	URL        string `json:"url,omitempty"`
+	Last       bool   `json:"last_destination"`
+}
+
+// Latest is the most recent completed backup.
+type Latest struct {
+	Path string    `json:"path"`
+	Time time.Time `json:"time,omitzero"`
+}
+
+// Check is the result of comparing the latest backup against a maximum age.
+type Check struct {
+	OK            bool      `json:"ok"`
+	LastBackup    time.Time `json:"last_backup,omitzero"`
+	AgeSeconds    int64     `json:"age_seconds,omitempty"`
+	MaxAgeSeconds int64     `json:"max_age_seconds"`
+	Running       bool      `json:"running"`
+	Reason        string    `json:"reason,omitempty"`
+}
+
+// GetStatus returns the state of the current backup.
+func GetStatus() (*Status, error) {
+	out, err := exec.Command("tmutil", "status", "-X").Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to run tmutil status: %w", err)
+	}
+	return parseStatus(out)
+}
+
+// GetDestinations returns the configured backup destinations.
+func GetDestinations() ([]Destination, error) {
+	out, err := exec.Command("tmutil", "destinationinfo", "-X").Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to run tmutil destinationinfo: %w", err)
+	}
+	return parseDestinations(out)
+}
+
+// GetLatest returns the most recent completed backup. tmutil can only
+// report it while the destination is reachable.
+func GetLatest() (*Latest, error) {
+	out, err := exec.Command("tmutil", "latestbackup").CombinedOutput()
+	if err != nil {
+		msg := strings.TrimSpace(string(out))
+		if msg == "" {
+			msg = err.Error()
+		}
+		return nil, fmt.Errorf("failed to get latest backup: %s", msg)
+	}
+	return parseLatest(string(out))
+}
+
+// CheckAge reports whether the latest backup is newer than maxAge. A nil
+// latest counts as stale.
+func CheckAge(latest *Latest, running bool, maxAge time.Duration, now time.Time) Check {
+	c := Check{MaxAgeSeconds: int64(maxAge.Seconds()), Running: running}
+
+	if latest == nil || latest.Time.IsZero() {
+		c.Reason = "no completed backup found"
+		return c
+	}
+
+	c.LastBackup = latest.Time
+	age := now.Sub(latest.Time)
+	c.AgeSeconds = int64(age.Seconds())
+	if age > maxAge {
+		c.Reason = fmt.Sprintf("last backup is %s old, older than %s", age.Round(time.Minute), maxAge)
+		return c
+	}
+
+	c.OK = true
+	return c
+}
+
+func parseStatus(data []byte) (*Status, error) {
+	decoded, err := plist.Decode(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse tmutil status: %w", err)
+	}
+	m, ok := decoded.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("unexpected tmutil status output")
+	}
+
+	s := &Status{
+		Running:          number(m["Running"]) != 0,
+		Phase:            str(m["BackupPhase"]),
+		DestinationID:    str(m["DestinationID"]),
+		DestinationMount: str(m["DestinationMountPoint"]),
+	}
+
+	// Progress figures appear both at the top level and, on newer macOS,
+	// in a Progress dictionary.
+	percent := number(m["Percent"])
+	if p, ok := m["Progress"].(map[string]any); ok {
+		if v := number(p["Percent"]); v > 0 {
+			percent = v
+		}
+		s.BytesCopied = int64(number(p["bytes"]))
+		s.TotalBytes = int64(number(p["totalBytes"]))
+		s.FilesCopied = int64(number(p["files"]))
+		s.TotalFiles = int64(number(p["totalFiles"]))
+		s.RemainingSeconds = int64(number(p["TimeRemaining"]))
+	}
+	// tmutil reports a fraction, or -1 before the size is known.
+	if percent > 0 {
+		s.Percent = percent * 100
+	}
+
+	return s, nil
+}
+
+func parseDestinations(data []byte) ([]Destination, error) {
+	var raw struct {
+		Destinations []map[string]any `json:"Destinations"`
+	}
+	if err := plist.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse tmutil destinationinfo: %w", err)
+	}
+
+	dests := []Destination{}
+	for _, d := range raw.Destinations {
+		dests = append(dests, Destination{
+			ID:         str(d["ID"]),
+			Name:       str(d["Name"]),
+			Kind:       str(d["Kind"]),
+			MountPoint: str(d["MountPoint"]),
+			URL:        str(d["URL"]),
+			Last:       number(d["LastDestination"]) != 0,
+		})
+	}
+	return dests, nil
+}
+
+func parseLatest(output string) (*Latest, error) {
+	path := strings.TrimSpace(output)
+	if i := strings.LastIndex(path, "\n"); i >= 0 {
+		path = strings.TrimSpace(path[i+1:])
+	}
+
+	matches := backupDateRe.FindAllString(path, -1)
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("unexpected tmutil latestbackup output: %q", path)
+	}
+	t, err := time.ParseInLocation(backupDateLayout, matches[len(matches)-1], time.Local)
+	if err != nil {
+		return nil, fmt.Errorf("invalid backup date in %q: %w", path, err)
+	}
+	return &Latest{Path: path, Time: t}, nil
+}
+
+func str(v any) string {
+	s, _ := v.(string)
+	return s
+}
+
+// number converts a decoded plist value to a float64. tmutil writes some
+// numbers as strings and some flags as integers or booleans.
+func number(v any) float64 {
+	switch n := v.(type) {
+	case int64:
+		return float64(n)
+	case uint64:
+		return float64(n)
+	case float64:
+		return n
+	case bool:
+		if n {
+			return 1
+		}
+	case string:
+		f, _ := strconv.ParseFloat(n, 64)
+		return f
+	}
+	return 0
+}
+
+// ParseDuration delegates to power.ParseDuration for consistent duration parsing.
+var ParseDuration = power.ParseDuration
@@ -0,0 +1,178 @@
+package backup
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+const statusRunningPlist = `<?xml version="1.0" encoding="UTF-8"?>
+<plist version="1.0">
+<dict>
+	<key>BackupPhase</key>
+	<string>Copying</string>
+	<key>ClientID</key>
+	<string>com.apple.backupd</string>
+	<key>DestinationID</key>
+	<string>4B1C7E2A-0000-0000-0000-000000000001</string>
+	<key>DestinationMountPoint</key>
+	<string>/Volumes/Backups</string>
+	<key>Percent</key>
+	<string>0.25</string>
+	<key>Progress</key>
+	<dict>
+		<key>Percent</key>
+		<real>0.42</real>
+		<key>TimeRemaining</key>
+		<integer>600</integer>
+		<key>bytes</key>
+		<integer>4200000000</integer>
+		<key>files</key>
+		<integer>1200</integer>
+		<key>totalBytes</key>
+		<integer>10000000000</integer>
+		<key>totalFiles</key>
+		<integer>3000</integer>
+	</dict>
+	<key>Running</key>
+	<integer>1</integer>
+	<key>Stopping</key>
+	<integer>0</integer>
+</dict>
+</plist>
+`
+
+func TestParseStatusRunning(t *testing.T) {
+	s, err := parseStatus([]byte(statusRunningPlist))
+	if err != nil {
+		t.Fatalf("parseStatus: %v", err)
+	}
+
+	want := Status{
+		Running:          true,
+		Phase:            "Copying",
+		Percent:          42,
+		BytesCopied:      4200000000,
+		TotalBytes:       10000000000,
+		FilesCopied:      1200,
+		TotalFiles:       3000,
+		RemainingSeconds: 600,
+		DestinationID:    "4B1C7E2A-0000-0000-0000-000000000001",
+		DestinationMount: "/Volumes/Backups",
+	}
+	if *s != want {
+		t.Errorf("parseStatus =\n%+v\nwant\n%+v", *s, want)
+	}
+}
+
+func TestParseStatusIdle(t *testing.T) {
+	input := `<plist><dict>
+		<key>ClientID</key><string>com.apple.backupd</string>
+		<key>Percent</key><string>-1</string>
+		<key>Running</key><false/>
+	</dict></plist>`
+
+	s, err := parseStatus([]byte(input))
+	if err != nil {
+		t.Fatalf("parseStatus: %v", err)
+	}
+	if s.Running || s.Phase != "" || s.Percent != 0 {
+		t.Errorf("idle status = %+v", s)
+	}
+
+	if _, err := parseStatus([]byte("<plist><array/></plist>")); err == nil {
+		t.Error("expected error for non-dictionary output")
+	}
+}
+
+func TestParseDestinations(t *testing.T) {
+	input := `<plist><dict><key>Destinations</key><array>
+		<dict>
+			<key>ID</key><string>AAA</string>
+			<key>Kind</key><string>Local</string>
+			<key>LastDestination</key><integer>1</integer>
+			<key>MountPoint</key><string>/Volumes/Backups</string>
+			<key>Name</key><string>Backups</string>
+		</dict>
+		<dict>
+			<key>ID</key><string>BBB</string>
+			<key>Kind</key><string>Network</string>
+			<key>LastDestination</key><integer>0</integer>
+			<key>Name</key><string>NAS</string>
+			<key>URL</key><string>smb://nas.local/tm</string>
+		</dict>
+	</array></dict></plist>`
+
+	dests, err := parseDestinations([]byte(input))
+	if err != nil {
+		t.Fatalf("parseDestinations: %v", err)
+	}
+	if len(dests) != 2 {
+		t.Fatalf("expected 2 destinations, got %+v", dests)
+	}
+	if d := dests[0]; d.ID != "AAA" || d.Kind != "Local" || !d.Last || d.MountPoint != "/Volumes/Backups" {
+		t.Errorf("local destination = %+v", d)
+	}
+	if d := dests[1]; d.Name != "NAS" || d.Last || d.URL != "smb://nas.local/tm" {
+		t.Errorf("network destination = %+v", d)
+	}
+
+	none, err := parseDestinations([]byte(`<plist><dict></dict></plist>`))
+	if err != nil || none == nil || len(none) != 0 {
+		t.Errorf("no destinations = %v, %v; want empty slice", none, err)
+	}
+}
+
+func TestParseLatest(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   time.Time
+	}{
+		{
+			name:   "apfs",
+			output: "/Volumes/.timemachine/ABC/2026-10-17-093012.backup/2026-10-17-093012.backup\n",
+			want:   time.Date(2026, 10, 17, 9, 30, 12, 0, time.Local),
+		},
+		{
+			name:   "hfs",
+			output: "/Volumes/Backups/Backups.backupdb/Mac/2026-10-16-213455\n",
+			want:   time.Date(2026, 10, 16, 21, 34, 55, 0, time.Local),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l, err := parseLatest(tt.output)
+			if err != nil {
+				t.Fatalf("parseLatest: %v", err)
+			}
+			if !l.Time.Equal(tt.want) || l.Path != strings.TrimSpace(tt.output) {
+				t.Errorf("parseLatest = %+v, want time %v", l, tt.want)
+			}
+		})
+	}
+
+	if _, err := parseLatest("No machine directory found for host.\n"); err == nil {
+		t.Error("expected error for output without a backup path")
+	}
+}
+
+func TestCheckAge(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	maxAge := 48 * time.Hour
+
+	fresh := CheckAge(&Latest{Time: now.Add(-24 * time.Hour)}, false, maxAge, now)
+	if !fresh.OK || fresh.AgeSeconds != 86400 || fresh.MaxAgeSeconds != 172800 || fresh.Reason != "" {
+		t.Errorf("fresh = %+v", fresh)
+	}
+
+	stale := CheckAge(&Latest{Time: now.Add(-72 * time.Hour)}, true, maxAge, now)
+	if stale.OK || !stale.Running || !strings.Contains(stale.Reason, "72h0m0s old") {
+		t.Errorf("stale = %+v", stale)
+	}
+
+	missing := CheckAge(nil, false, maxAge, now)
+	if missing.OK || missing.Reason == "" {
+		t.Errorf("missing = %+v", missing)
+	}
+}
@@ -0,0 +1,138 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/lu-zhengda/macctl/internal/agent"
+	"github.com/lu-zhengda/macctl/internal/power"
+	"github.com/lu-zhengda/macctl/internal/recorder"
+)
+
+var (
+	agentEvery   string
+	agentDomains string
+)
+
+var agentCmd = &cobra.Command{
+	Use:   "agent",
+	Short: "Manage the background recorder launch agent",
+	Long: `Install, remove, or inspect a launchd agent that runs 'macctl record'
+in the background so histories stay fresh without cron.`,
+}
+
+var agentInstallCmd = &cobra.Command{
+	Use:   "install",
+	Short: "Install and load the recorder launch agent",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if _, err := power.ParseDuration(agentEvery); err != nil {
+			return fmt.Errorf("invalid interval: %w", err)
+		}
+		domains, err := recorder.ParseDomains(agentDomains)
+		if err != nil {
+			return err
+		}
+
+		cfg, err := agent.DefaultConfig(agentEvery, domains)
+		if err != nil {
+			return err
+		}
+
+		path, err := agent.Install(*cfg)
+		if err != nil {
+			return fmt.Errorf("failed to install agent: %w", err)
+		}
+
+		if jsonFlag {
+			return printJSON(map[string]any{
+				"plist_path":  path,
+				"interval":    cfg.Interval,
+				"domains":     cfg.Domains,
+				"stdout_path": cfg.StdoutPath,
+				"stderr_path": cfg.StderrPath,
+			})
+		}
+
+		fmt.Printf("Installed launch agent: %s\n", path)
+		fmt.Printf("Recording %s every %s\n", strings.Join(cfg.Domains, ", "), cfg.Interval)
+		fmt.Printf("Logs: %s\n", cfg.StdoutPath)
+		return nil
+	},
+}
+
+var agentUninstallCmd = &cobra.Command{
+	Use:   "uninstall",
+	Short: "Unload and remove the recorder launch agent",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := agent.Uninstall(); err != nil {
+			return fmt.Errorf("failed to uninstall agent: %w", err)
+		}
+
+		if jsonFlag {
+			return printJSON(map[string]bool{"uninstalled": true})
+		}
+
+		fmt.Println("Launch agent uninstalled.")
+		return nil
+	},
+}
+
+var agentStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show recorder launch agent status",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		s, err := agent.GetStatus()
+		if err != nil {
+			return fmt.Errorf("failed to get agent status: %w", err)
+		}
+
+		if jsonFlag {
+			return printJSON(s)
+		}
+
+		if !s.Installed && !s.Loaded {
+			fmt.Println("Launch agent not installed. Use 'macctl agent install' to set it up.")
+			return nil
+		}
+
+		state := "not loaded"
+		if s.Loaded {
+			state = s.State
+			if s.PID > 0 {
+				state = fmt.Sprintf("%s (pid %d)", state, s.PID)
+			}
+		}
+
+		lastExit := "never exited"
+		if s.LastExitCode != nil {
+			lastExit = fmt.Sprintf("%d", *s.LastExitCode)
+		}
+
+		lastRun := "unknown"
+		if !s.LastRun.IsZero() {
+			lastRun = s.LastRun.Local().Format("2006-01-02 15:04:05")
+		}
+
+		fmt.Printf("Plist:      %s\n", s.PlistPath)
+		fmt.Printf("State:      %s\n", state)
+		fmt.Printf("Runs:       %d\n", s.Runs)
+		fmt.Printf("Last Exit:  %s\n", lastExit)
+		fmt.Printf("Last Run:   %s\n", lastRun)
+		if s.StdoutPath != "" {
+			fmt.Printf("Log:        %s\n", s.StdoutPath)
+		}
+		return nil
+	},
+}
+
+func init() {
+	agentInstallCmd.Flags().StringVar(&agentEvery, "every", recorder.DefaultInterval, "Recording interval (e.g., 5m, 1h)")
+	agentInstallCmd.Flags().StringVar(&agentDomains, "domains", "power,disk,thermal,events", "Comma-separated domains to record (power, disk, thermal, events)")
+
+	agentCmd.AddCommand(agentInstallCmd)
+	agentCmd.AddCommand(agentUninstallCmd)
+	agentCmd.AddCommand(agentStatusCmd)
+	rootCmd.AddCommand(agentCmd)
+}
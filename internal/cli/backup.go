@@ -0,0 +1,158 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/lu-zhengda/macctl/internal/backup"
+	"github.com/lu-zhengda/macctl/internal/disk"
+)
+
+var backupCmd = &cobra.Command{
+	Use:   "backup",
+	Short: "Time Machine backup status",
+	Long:  `Inspect Time Machine: the running backup, destinations, and the age of the last backup.`,
+}
+
+// backupStatusOutput is the JSON shape of `backup status`.
+type backupStatusOutput struct {
+	*backup.Status
+	Latest     *backup.Latest `json:"latest,omitempty"`
+	AgeSeconds int64          `json:"age_seconds,omitempty"`
+}
+
+var backupStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show the current backup and the last successful one",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		s, err := backup.GetStatus()
+		if err != nil {
+			return fmt.Errorf("failed to get backup status: %w", err)
+		}
+
+		// The latest backup is unknown while the destination is unreachable.
+		latest, latestErr := backup.GetLatest()
+
+		if jsonFlag {
+			out := backupStatusOutput{Status: s, Latest: latest}
+			if latest != nil {
+				out.AgeSeconds = int64(time.Since(latest.Time).Seconds())
+			}
+			return printJSON(out)
+		}
+
+		if s.Running {
+			fmt.Printf("Backup:       running (%s)\n", s.Phase)
+			fmt.Printf("Progress:     %.1f%%\n", s.Percent)
+			if s.TotalBytes > 0 {
+				fmt.Printf("Copied:       %s of %s\n", disk.FormatBytes(s.BytesCopied), disk.FormatBytes(s.TotalBytes))
+			}
+			if s.RemainingSeconds > 0 {
+				fmt.Printf("Remaining:    %s\n", (time.Duration(s.RemainingSeconds) * time.Second).String())
+			}
+			if s.DestinationMount != "" {
+				fmt.Printf("Destination:  %s\n", s.DestinationMount)
+			}
+		} else {
+			fmt.Println("Backup:       idle")
+		}
+
+		if latest != nil {
+			fmt.Printf("Last Backup:  %s (%s ago)\n",
+				latest.Time.Format("2006-01-02 15:04"), time.Since(latest.Time).Round(time.Minute))
+		} else {
+			fmt.Printf("Last Backup:  unknown (%v)\n", latestErr)
+		}
+		return nil
+	},
+}
+
+var backupDestinationsCmd = &cobra.Command{
+	Use:   "destinations",
+	Short: "List backup destinations",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dests, err := backup.GetDestinations()
+		if err != nil {
+			return fmt.Errorf("failed to get backup destinations: %w", err)
+		}
+
+		if jsonFlag {
+			return printJSON(dests)
+		}
+
+		if len(dests) == 0 {
+			fmt.Println("No backup destinations configured.")
+			return nil
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "NAME\tKIND\tMOUNT\tLAST\tID")
+		for _, d := range dests {
+			mount := d.MountPoint
+			if mount == "" {
+				mount = "-"
+			}
+			last := ""
+			if d.Last {
+				last = "*"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", d.Name, d.Kind, mount, last, d.ID)
+		}
+		w.Flush()
+		return nil
+	},
+}
+
+var backupCheckMaxAge string
+
+var backupCheckCmd = &cobra.Command{
+	Use:   "check",
+	Short: "Exit non-zero if the last backup is too old",
+	Long: `Check that the last successful Time Machine backup is newer than --max-age
+and exit with a non-zero status if it is not, for use in scripts and
+monitoring.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		maxAge, err := backup.ParseDuration(backupCheckMaxAge)
+		if err != nil {
+			return fmt.Errorf("invalid --max-age: %w", err)
+		}
+
+		running := false
+		if s, err := backup.GetStatus(); err == nil {
+			running = s.Running
+		}
+		latest, latestErr := backup.GetLatest()
+
+		c := backup.CheckAge(latest, running, maxAge, time.Now())
+		if latest == nil && latestErr != nil {
+			c.Reason = latestErr.Error()
+		}
+
+		if jsonFlag {
+			if err := printJSON(c); err != nil {
+				return err
+			}
+		} else if c.OK {
+			fmt.Printf("OK: last backup %s (%s ago)\n",
+				c.LastBackup.Format("2006-01-02 15:04"), time.Since(c.LastBackup).Round(time.Minute))
+		}
+
+		if !c.OK {
+			return fmt.Errorf("backup is stale: %s", c.Reason)
+		}
+		return nil
+	},
+}
+
+func init() {
+	backupCheckCmd.Flags().StringVar(&backupCheckMaxAge, "max-age", backup.DefaultMaxAge, "Maximum age of the last backup (e.g., 48h, 7d)")
+
+	backupCmd.AddCommand(backupStatusCmd)
+	backupCmd.AddCommand(backupDestinationsCmd)
+	backupCmd.AddCommand(backupCheckCmd)
+	rootCmd.AddCommand(backupCmd)
+}
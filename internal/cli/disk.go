@@ -1,9 +1,15 @@
 package cli
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
+	"os/signal"
+	"strings"
+	"syscall"
 	"text/tabwriter"
+	"time"
 
 	"github.com/spf13/cobra"
 
@@ -13,55 +19,673 @@ import (
 var diskCmd = &cobra.Command{
 	Use:   "disk",
 	Short: "SSD health, I/O stats, and wear trends",
-	Long:  `Inspect SSD health, view current I/O rates, and track wear over time.`,
+	Long: `Inspect SSD health, view current I/O rates, and track wear over time.
+
+Commands that take a device default to the internal disk (disk0); pass a
+device such as disk4 or /dev/disk4, or --all for every disk.`,
+}
+
+var diskAll bool
+
+// diskTargets returns the devices a disk command should act on: the given
+// device, every disk with --all, or the default disk. Disk images are
+// skipped by --all unless includeImages is set.
+func diskTargets(args []string, all, includeImages bool) ([]string, error) {
+	if all {
+		if len(args) > 0 {
+			return nil, fmt.Errorf("--all cannot be combined with a device")
+		}
+		disks, err := disk.List()
+		if err != nil {
+			return nil, fmt.Errorf("failed to list disks: %w", err)
+		}
+		var devices []string
+		for _, d := range disks {
+			if d.Type == disk.TypeDiskImage && !includeImages {
+				continue
+			}
+			devices = append(devices, d.Device)
+		}
+		return devices, nil
+	}
+
+	if len(args) > 0 {
+		return []string{disk.NormalizeDevice(args[0])}, nil
+	}
+	return []string{disk.DefaultDevice}, nil
+}
+
+func diskLocation(info *disk.Info) string {
+	loc := "external"
+	if info.Internal {
+		loc = "internal"
+	}
+	if info.Removable {
+		loc += ", removable"
+	}
+	return loc
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
+
+var diskListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List physical disks and disk images",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		disks, err := disk.List()
+		if err != nil {
+			return fmt.Errorf("failed to list disks: %w", err)
+		}
+
+		if jsonFlag {
+			return printJSON(disks)
+		}
+
+		if len(disks) == 0 {
+			fmt.Println("No disks found.")
+			return nil
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "DEVICE\tTYPE\tPROTOCOL\tSIZE\tMODEL\tVOLUMES")
+		for _, d := range disks {
+			var names []string
+			for _, v := range d.Volumes {
+				if v.Name != "" {
+					names = append(names, v.Name)
+				}
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
+				d.Device, d.Type, d.Protocol, d.SizeHuman, d.Model, strings.Join(names, ", "))
+		}
+		w.Flush()
+		return nil
+	},
 }
 
+var diskStatusSmart bool
+
 var diskStatusCmd = &cobra.Command{
-	Use:   "status",
+	Use:   "status [device]",
 	Short: "Show SSD health status",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		devices, err := diskTargets(args, diskAll, false)
+		if err != nil {
+			return err
+		}
+
+		var healths []*disk.Health
+		for _, dev := range devices {
+			h, err := disk.GetHealth(dev)
+			if err != nil {
+				return fmt.Errorf("failed to get disk health for %s: %w", dev, err)
+			}
+			if diskStatusSmart {
+				smart, err := disk.GetSmart(dev)
+				switch {
+				case err == nil:
+					h.Smart = smart
+				case len(devices) == 1 || errors.Is(err, disk.ErrSmartctlNotFound):
+					return fmt.Errorf("failed to read SMART attributes for %s: %w", dev, err)
+				default:
+					// One unreadable disk shouldn't hide the others.
+					h.SmartError = err.Error()
+				}
+			}
+			healths = append(healths, h)
+		}
+
+		if jsonFlag {
+			if !diskAll {
+				return printJSON(healths[0])
+			}
+			return printJSON(healths)
+		}
+
+		for i, h := range healths {
+			if i > 0 {
+				fmt.Println()
+			}
+			fmt.Printf("Device:       %s\n", h.Device)
+			fmt.Printf("Model:        %s\n", h.Model)
+			fmt.Printf("Protocol:     %s\n", h.Protocol)
+			fmt.Printf("Size:         %s\n", h.SizeHuman)
+			fmt.Printf("SMART Status: %s\n", h.SmartStatus)
+			fmt.Printf("Wear Level:   %s\n", h.WearLevel)
+			fmt.Printf("Data Written: %s\n", h.DataWritten)
+			if info := h.Info; info != nil {
+				fmt.Printf("Location:     %s\n", diskLocation(info))
+				fmt.Printf("Solid State:  %s\n", yesNo(info.SolidState))
+				fmt.Printf("TRIM:         %s\n", yesNo(info.TRIMSupported))
+				if info.BlockSize > 0 {
+					fmt.Printf("Block Size:   %d bytes\n", info.BlockSize)
+				}
+				fmt.Printf("Encrypted:    %s\n", yesNo(info.Encrypted || info.FileVault))
+			}
+			if s := h.Smart; s != nil {
+				printSmart(s)
+			}
+			if h.SmartError != "" {
+				fmt.Printf("SMART Attrs:  unavailable (%s)\n", h.SmartError)
+			}
+		}
+		return nil
+	},
+}
+
+func printSmart(s *disk.SmartAttributes) {
+	fmt.Println()
+	fmt.Println("SMART Attributes (smartctl):")
+	if s.Passed != nil {
+		result := "PASSED"
+		if !*s.Passed {
+			result = "FAILED"
+		}
+		fmt.Printf("  Overall:           %s\n", result)
+	}
+	fmt.Printf("  Critical Warning:  0x%02x\n", s.CriticalWarning)
+	fmt.Printf("  Temperature:       %d°C\n", s.TemperatureC)
+	fmt.Printf("  Available Spare:   %d%% (threshold %d%%)\n", s.AvailableSpare, s.AvailableSpareThreshold)
+	fmt.Printf("  Percentage Used:   %d%%\n", s.PercentageUsed)
+	fmt.Printf("  Data Read:         %s\n", disk.FormatBytes(s.DataReadBytes))
+	fmt.Printf("  Data Written:      %s\n", disk.FormatBytes(s.DataWrittenBytes))
+	fmt.Printf("  Power-On Hours:    %d\n", s.PowerOnHours)
+	fmt.Printf("  Power Cycles:      %d\n", s.PowerCycles)
+	fmt.Printf("  Unsafe Shutdowns:  %d\n", s.UnsafeShutdowns)
+	fmt.Printf("  Media Errors:      %d\n", s.MediaErrors)
+	fmt.Printf("  Error Log Entries: %d\n", s.ErrorLogEntries)
+}
+
+var (
+	diskUsageLow      float64
+	diskUsageCritical float64
+)
+
+var diskUsageCmd = &cobra.Command{
+	Use:   "usage",
+	Short: "Show APFS container and volume space usage",
+	Long: `Show used and free space of each APFS container and its volumes, with
+volume roles, purgeable space and encryption. Containers with less free
+space than --low or --critical percent are flagged.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if diskUsageCritical > diskUsageLow {
+			return fmt.Errorf("--critical (%.0f%%) must not exceed --low (%.0f%%)", diskUsageCritical, diskUsageLow)
+		}
+
+		usage, err := disk.GetUsage(disk.Thresholds{LowPercent: diskUsageLow, CriticalPercent: diskUsageCritical})
+		if err != nil {
+			return fmt.Errorf("failed to get disk usage: %w", err)
+		}
+
+		if jsonFlag {
+			return printJSON(usage)
+		}
+
+		if len(usage.Containers) == 0 {
+			fmt.Println("No APFS containers found.")
+			return nil
+		}
+
+		for i, c := range usage.Containers {
+			if i > 0 {
+				fmt.Println()
+			}
+			status := ""
+			switch c.Status {
+			case disk.SpaceOK:
+			case disk.SpaceUnknown:
+				status = "  [UNKNOWN: capacity not reported]"
+			default:
+				status = fmt.Sprintf("  [%s: below %.0f%% free]", strings.ToUpper(c.Status), usageThreshold(usage.Thresholds, c.Status))
+			}
+			fmt.Printf("Container %s (%s): %s used, %s free of %s (%.1f%% free)%s\n",
+				c.Device, c.PhysicalStore, disk.FormatBytes(c.UsedBytes), disk.FormatBytes(c.FreeBytes),
+				disk.FormatBytes(c.CapacityBytes), c.FreePercent, status)
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+			fmt.Fprintln(w, "DEVICE\tNAME\tROLE\tUSED\tPURGEABLE\tENCRYPTED\tMOUNT")
+			for _, v := range c.Volumes {
+				role, purgeable, mount := v.Role, "-", v.MountPoint
+				if role == "" {
+					role = "-"
+				}
+				if v.PurgeableBytes > 0 {
+					purgeable = disk.FormatBytes(v.PurgeableBytes)
+				}
+				if mount == "" {
+					mount = "-"
+				}
+				encrypted := yesNo(v.Encrypted || v.FileVault)
+				if v.Locked {
+					encrypted += " (locked)"
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+					v.Device, v.Name, role, disk.FormatBytes(v.UsedBytes), purgeable, encrypted, mount)
+			}
+			w.Flush()
+		}
+		return nil
+	},
+}
+
+func usageThreshold(t disk.Thresholds, status string) float64 {
+	if status == disk.SpaceCritical {
+		return t.CriticalPercent
+	}
+	return t.LowPercent
+}
+
+var (
+	diskSnapshotsVolume string
+	diskThinKeep        int
+	diskThinFree        string
+	diskThinDryRun      bool
+	diskThinYes         bool
+)
+
+var diskSnapshotsCmd = &cobra.Command{
+	Use:   "snapshots",
+	Short: "List APFS local snapshots",
+	Long: `List the APFS local snapshots (such as Time Machine's) of a volume, oldest
+first. Local snapshots hold on to deleted data and are a common cause of a
+full disk; reclaim their space with 'disk snapshots thin'.
+
+Snapshot sizes are not shown: neither tmutil nor diskutil reports how much
+space an individual APFS snapshot holds. 'disk snapshots thin' reports the
+space actually freed.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		h, err := disk.GetHealth()
+		snaps, err := disk.ListSnapshots(diskSnapshotsVolume)
 		if err != nil {
-			return fmt.Errorf("failed to get disk health: %w", err)
+			return fmt.Errorf("failed to list local snapshots: %w", err)
 		}
 
 		if jsonFlag {
-			return printJSON(h)
+			if snaps == nil {
+				snaps = []disk.LocalSnapshot{}
+			}
+			return printJSON(snaps)
 		}
 
-		fmt.Printf("Device:       %s\n", h.Device)
-		fmt.Printf("Model:        %s\n", h.Model)
-		fmt.Printf("Protocol:     %s\n", h.Protocol)
-		fmt.Printf("Size:         %s\n", h.SizeHuman)
-		fmt.Printf("SMART Status: %s\n", h.SmartStatus)
-		fmt.Printf("Wear Level:   %s\n", h.WearLevel)
-		fmt.Printf("Data Written: %s\n", h.DataWritten)
+		if len(snaps) == 0 {
+			fmt.Printf("No local snapshots on %s.\n", diskSnapshotsVolume)
+			return nil
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "DATE\tAGE\tNAME")
+		for _, s := range snaps {
+			date, age := "-", "-"
+			if !s.Date.IsZero() {
+				date = s.Date.Format("2006-01-02 15:04:05")
+				age = time.Since(s.Date).Round(time.Minute).String()
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\n", date, age, s.Name)
+		}
+		w.Flush()
 		return nil
 	},
 }
 
+var diskSnapshotsThinCmd = &cobra.Command{
+	Use:   "thin",
+	Short: "Delete local snapshots to reclaim space",
+	Long: `Delete APFS local snapshots to reclaim disk space, either keeping only the
+newest N (--keep) or letting macOS thin them oldest first until the given
+amount is freed (--free 20G). Asks for confirmation unless --yes is given.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		keepSet, freeSet := cmd.Flags().Changed("keep"), cmd.Flags().Changed("free")
+		if keepSet == freeSet {
+			return fmt.Errorf("specify exactly one of --keep or --free")
+		}
+
+		result := &disk.ThinResult{Volume: diskSnapshotsVolume, DryRun: diskThinDryRun, Deleted: []disk.LocalSnapshot{}}
+
+		snaps, err := disk.ListSnapshots(diskSnapshotsVolume)
+		if err != nil {
+			return fmt.Errorf("failed to list local snapshots: %w", err)
+		}
+
+		var prompt string
+		if keepSet {
+			if diskThinKeep < 0 {
+				return fmt.Errorf("--keep must not be negative")
+			}
+			result.Keep = &diskThinKeep
+			victims := disk.SnapshotsBeyond(snaps, diskThinKeep)
+			if len(victims) == 0 {
+				if jsonFlag {
+					return printJSON(result)
+				}
+				fmt.Printf("No more than %d Time Machine snapshots on %s; nothing to thin.\n", diskThinKeep, diskSnapshotsVolume)
+				return nil
+			}
+			result.Deleted = victims
+			prompt = fmt.Sprintf("Delete %d local snapshots on %s, keeping the newest %d Time Machine snapshots?", len(victims), diskSnapshotsVolume, diskThinKeep)
+		} else {
+			target, err := disk.ParseBytes(diskThinFree)
+			if err != nil || target <= 0 {
+				return fmt.Errorf("invalid --free size %q", diskThinFree)
+			}
+			result.FreeTarget = target
+			prompt = fmt.Sprintf("Thin local snapshots on %s until %s is reclaimed?", diskSnapshotsVolume, disk.FormatBytes(target))
+		}
+
+		if diskThinDryRun {
+			if jsonFlag {
+				return printJSON(result)
+			}
+			if keepSet {
+				fmt.Printf("Would delete %d local snapshots:\n", len(result.Deleted))
+				for _, s := range result.Deleted {
+					fmt.Printf("  %s\n", s.Name)
+				}
+			} else {
+				fmt.Printf("Would ask macOS to thin %d local snapshots on %s, oldest first, until %s is reclaimed.\n",
+					len(snaps), diskSnapshotsVolume, disk.FormatBytes(result.FreeTarget))
+			}
+			return nil
+		}
+
+		if !diskThinYes && !confirm(prompt) {
+			return fmt.Errorf("aborted")
+		}
+
+		before, _ := disk.FreeBytes(diskSnapshotsVolume)
+		if keepSet {
+			for _, s := range result.Deleted {
+				if err := disk.DeleteSnapshot(s); err != nil {
+					return err
+				}
+			}
+		} else {
+			dates, err := disk.ThinSnapshots(diskSnapshotsVolume, result.FreeTarget)
+			if err != nil {
+				return err
+			}
+			thinned := make(map[string]bool)
+			for _, d := range dates {
+				thinned[d] = true
+			}
+			for _, s := range snaps {
+				if thinned[s.DateString()] {
+					result.Deleted = append(result.Deleted, s)
+				}
+			}
+		}
+		if after, err := disk.FreeBytes(diskSnapshotsVolume); err == nil && before > 0 && after > before {
+			result.FreedBytes = after - before
+		}
+
+		if jsonFlag {
+			return printJSON(result)
+		}
+		for _, s := range result.Deleted {
+			fmt.Printf("Deleted %s\n", s.Name)
+		}
+		fmt.Printf("Deleted %d local snapshots, %s freed.\n", len(result.Deleted), disk.FormatBytes(result.FreedBytes))
+		return nil
+	},
+}
+
+var diskForce bool
+
+// printBusy prints the processes blocking an unmount, as JSON or a table.
+func printBusy(busy *disk.BusyError) {
+	if jsonFlag {
+		printJSON(busy)
+		return
+	}
+	if len(busy.Processes) == 0 {
+		return
+	}
+	w := tabwriter.NewWriter(os.Stderr, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "PID\tUSER\tCOMMAND")
+	for _, p := range busy.Processes {
+		fmt.Fprintf(w, "%d\t%s\t%s\n", p.PID, p.User, p.Command)
+	}
+	w.Flush()
+}
+
+// diskActionError prints blocking processes for busy volumes and returns err.
+// Joined errors, as from ejecting several disks, are each inspected.
+func diskActionError(err error) error {
+	errs := []error{err}
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		errs = joined.Unwrap()
+	}
+	for _, e := range errs {
+		var busy *disk.BusyError
+		if errors.As(e, &busy) {
+			printBusy(busy)
+		}
+	}
+	return err
+}
+
+var diskEjectCmd = &cobra.Command{
+	Use:   "eject <name|all-external>",
+	Short: "Unmount and eject an external disk",
+	Long: `Unmount every volume of a disk and eject it. The disk can be given by device
+(disk4), or by the name, mount point or device of one of its volumes.
+Use all-external to eject every external disk, e.g. before closing the lid.
+
+If a volume is busy, the processes using it are listed; --force unmounts
+regardless.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		result, err := disk.Eject(args[0], diskForce)
+		if result == nil {
+			return err
+		}
+
+		if jsonFlag {
+			// One document covering both the ejected and the failed disks.
+			if jerr := printJSON(result); jerr != nil {
+				return jerr
+			}
+			return err
+		}
+
+		for _, d := range result.Ejected {
+			fmt.Printf("Ejected %s (%s)\n", d.Device, d.Model)
+		}
+		if err != nil {
+			return diskActionError(err)
+		}
+		if len(result.Ejected) == 0 {
+			fmt.Println("No external disks to eject.")
+		}
+		return nil
+	},
+}
+
+var diskMountCmd = &cobra.Command{
+	Use:   "mount <volume>",
+	Short: "Mount a volume",
+	Long:  `Mount a volume given by name, mount point or device (e.g., disk4s1).`,
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		v, err := disk.MountVolume(args[0])
+		if err != nil {
+			return err
+		}
+		if jsonFlag {
+			return printJSON(v)
+		}
+		fmt.Printf("Mounted %s (%s)\n", volumeLabel(v), v.Device)
+		return nil
+	},
+}
+
+var diskUnmountCmd = &cobra.Command{
+	Use:   "unmount <volume>",
+	Short: "Unmount a volume",
+	Long: `Unmount a volume given by name, mount point or device (e.g., disk4s1).
+If the volume is busy, the processes using it are listed; --force unmounts
+regardless.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		v, err := disk.UnmountVolume(args[0], diskForce)
+		if err != nil {
+			return diskActionError(err)
+		}
+		if jsonFlag {
+			return printJSON(v)
+		}
+		fmt.Printf("Unmounted %s (%s)\n", volumeLabel(v), v.Device)
+		return nil
+	},
+}
+
+func volumeLabel(v *disk.Volume) string {
+	if v.Name != "" {
+		return v.Name
+	}
+	return v.Device
+}
+
+var diskIOInterval string
+var diskIOWatch bool
+
 var diskIOCmd = &cobra.Command{
-	Use:   "io",
+	Use:   "io [device]",
 	Short: "Show current I/O rates",
-	Long:  `Display current disk read/write throughput and IOPS.`,
+	Long: `Display current disk read/write throughput and IOPS, measured by sampling
+each disk's I/O counters twice, --interval apart.
+
+With --watch, keep sampling every --interval and print throughput, IOPS and
+average latency per disk until interrupted, then min/avg/max/p95 for the
+session. With --json, --watch emits one NDJSON line per disk per sample.`,
+	Args: cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		stats, err := disk.GetIOStats()
+		interval, err := time.ParseDuration(diskIOInterval)
+		if err != nil || interval <= 0 {
+			return fmt.Errorf("invalid interval: %q", diskIOInterval)
+		}
+
+		// With --all, every disk ioreg reports is sampled.
+		var devices []string
+		if !diskAll {
+			devices, err = diskTargets(args, false, true)
+			if err != nil {
+				return err
+			}
+		} else if len(args) > 0 {
+			return fmt.Errorf("--all cannot be combined with a device")
+		}
+
+		if diskIOWatch {
+			return watchDiskIO(interval, devices)
+		}
+
+		stats, err := disk.GetIOStats(interval, devices...)
 		if err != nil {
 			return fmt.Errorf("failed to get I/O stats: %w", err)
 		}
 
 		if jsonFlag {
+			if !diskAll && len(stats) == 1 {
+				return printJSON(stats[0])
+			}
 			return printJSON(stats)
 		}
 
-		fmt.Printf("Read:   %.2f MB/s  (%.0f IOPS)\n", stats.ReadMBs, stats.ReadIOPS)
-		fmt.Printf("Write:  %.2f MB/s  (%.0f IOPS)\n", stats.WriteMBs, stats.WriteIOPS)
+		if !diskAll && len(stats) == 1 {
+			fmt.Printf("Read:   %.2f MB/s  (%.0f IOPS)\n", stats[0].ReadMBs, stats[0].ReadIOPS)
+			fmt.Printf("Write:  %.2f MB/s  (%.0f IOPS)\n", stats[0].WriteMBs, stats[0].WriteIOPS)
+			return nil
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "DEVICE\tREAD_MB/S\tREAD_IOPS\tWRITE_MB/S\tWRITE_IOPS")
+		for _, s := range stats {
+			fmt.Fprintf(w, "%s\t%.2f\t%.0f\t%.2f\t%.0f\n",
+				s.Device, s.ReadMBs, s.ReadIOPS, s.WriteMBs, s.WriteIOPS)
+		}
+		w.Flush()
 		return nil
 	},
 }
 
+// watchDiskIO prints I/O rates every interval until interrupted, then a
+// summary of the session.
+func watchDiskIO(interval time.Duration, devices []string) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	tracker := disk.NewIOTracker()
+	const lineFmt = "%-8s  %-8s  %10s  %10s  %9s  %9s  %9s  %9s\n"
+	if !jsonFlag {
+		fmt.Printf(lineFmt, "TIME", "DEVICE", "READ_MB/S", "WRITE_MB/S", "READ_IOPS", "WRITE_IOPS", "READ_MS", "WRITE_MS")
+	}
+
+	err := disk.WatchIO(ctx, interval, devices, func(stats []disk.IOStats) {
+		tracker.Add(stats)
+		for _, s := range stats {
+			if jsonFlag {
+				printNDJSON(s)
+				continue
+			}
+			fmt.Printf(lineFmt,
+				s.Timestamp.Local().Format("15:04:05"), s.Device,
+				fmt.Sprintf("%.2f", s.ReadMBs), fmt.Sprintf("%.2f", s.WriteMBs),
+				fmt.Sprintf("%.0f", s.ReadIOPS), fmt.Sprintf("%.0f", s.WriteIOPS),
+				fmt.Sprintf("%.2f", s.ReadLatencyMS), fmt.Sprintf("%.2f", s.WriteLatencyMS))
+		}
+	})
+	if err != nil {
+		return fmt.Errorf("failed to watch I/O stats: %w", err)
+	}
+
+	summary := tracker.Summary()
+	if jsonFlag {
+		for _, s := range summary {
+			printNDJSON(map[string]any{"summary": s})
+		}
+		return nil
+	}
+	if len(summary) == 0 {
+		return nil
+	}
+
+	fmt.Println()
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "DEVICE\tMETRIC\tMIN\tAVG\tMAX\tP95")
+	for _, s := range summary {
+		metrics := []struct {
+			name string
+			d    disk.Distribution
+		}{
+			{"read MB/s", s.ReadMBs},
+			{"write MB/s", s.WriteMBs},
+			{"read IOPS", s.ReadIOPS},
+			{"write IOPS", s.WriteIOPS},
+			{"read ms", s.ReadLatencyMS},
+			{"write ms", s.WriteLatencyMS},
+		}
+		for _, m := range metrics {
+			fmt.Fprintf(w, "%s\t%s\t%.2f\t%.2f\t%.2f\t%.2f\n",
+				s.Device, m.name, m.d.Min, m.d.Avg, m.d.Max, m.d.P95)
+		}
+	}
+	w.Flush()
+	return nil
+}
+
 var diskHistoryLast string
 
+// diskHistoryOutput is the JSON shape of `disk history`.
+type diskHistoryOutput struct {
+	Snapshots   []disk.HealthSnapshot `json:"snapshots"`
+	DailyWrites []disk.DailyWrite     `json:"daily_writes"`
+}
+
 var diskHistoryCmd = &cobra.Command{
 	Use:   "history",
 	Short: "Show SSD wear trends over time",
@@ -87,8 +711,17 @@ var diskHistoryCmd = &cobra.Command{
 			snapshots = snapshots[len(snapshots)-disk.DefaultHistoryCount:]
 		}
 
+		daily := disk.DailyWrites(snapshots)
+
 		if jsonFlag {
-			return printJSON(snapshots)
+			out := diskHistoryOutput{Snapshots: snapshots, DailyWrites: daily}
+			if out.Snapshots == nil {
+				out.Snapshots = []disk.HealthSnapshot{}
+			}
+			if out.DailyWrites == nil {
+				out.DailyWrites = []disk.DailyWrite{}
+			}
+			return printJSON(out)
 		}
 
 		if len(snapshots) == 0 {
@@ -97,34 +730,137 @@ var diskHistoryCmd = &cobra.Command{
 		}
 
 		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
-		fmt.Fprintln(w, "TIMESTAMP\tMODEL\tSMART\tWEAR\tDATA_WRITTEN")
+		fmt.Fprintln(w, "TIMESTAMP\tDEVICE\tMODEL\tSMART\tWEAR\tDATA_WRITTEN")
 		for _, s := range snapshots {
-			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n",
+			device := s.Device
+			if device == "" {
+				device = disk.DefaultDevice
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
 				s.Timestamp.Local().Format("2006-01-02 15:04"),
-				s.Model, s.SmartStatus, s.WearLevel, s.DataWritten)
+				device, s.Model, s.SmartStatus, s.WearLevel, s.DataWritten)
 		}
 		w.Flush()
+
+		if len(daily) > 0 {
+			fmt.Println()
+			w = tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+			fmt.Fprintln(w, "DATE\tDEVICE\tWRITTEN")
+			for _, d := range daily {
+				fmt.Fprintf(w, "%s\t%s\t%s\n", d.Date, d.Device, disk.FormatBytes(d.Bytes))
+			}
+			w.Flush()
+		}
+		return nil
+	},
+}
+
+var diskForecastTBW float64
+
+var diskForecastCmd = &cobra.Command{
+	Use:   "forecast [device]",
+	Short: "Project days until rated endurance and full wear",
+	Long: `Project when a disk will reach its rated write endurance (TBW) and 100%
+wear, from the write and wear rates in recorded history.
+
+Pass the manufacturer's rating with --tbw; without it the endurance is
+estimated at 600 TB written per TB of capacity.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		device := disk.DefaultDevice
+		if len(args) > 0 {
+			device = args[0]
+		}
+		if diskForecastTBW < 0 {
+			return fmt.Errorf("--tbw must not be negative")
+		}
+
+		snapshots, err := disk.LoadHistory()
+		if err != nil {
+			return fmt.Errorf("failed to load disk history: %w", err)
+		}
+
+		f, err := disk.ComputeForecast(snapshots, device, int64(diskForecastTBW*1e12), time.Now())
+		if err != nil {
+			return err
+		}
+
+		if jsonFlag {
+			return printJSON(f)
+		}
+
+		fmt.Printf("Device:        %s\n", f.Device)
+		fmt.Printf("History:       %s to %s (%d samples)\n",
+			f.Since.Local().Format("2006-01-02"), f.Until.Local().Format("2006-01-02"), f.Samples)
+		fmt.Printf("Data Written:  %s\n", disk.FormatBytes(f.DataWrittenBytes))
+		fmt.Printf("Write Rate:    %s/day\n", disk.FormatBytes(int64(f.BytesPerDay)))
+		if f.RatedBytes > 0 {
+			rated := disk.FormatBytes(f.RatedBytes)
+			if f.RatedEstimated {
+				rated += " (estimated)"
+			}
+			fmt.Printf("Rated TBW:     %s\n", rated)
+		}
+		if f.DaysToRated != nil {
+			fmt.Printf("Rated Reached: %s (%s)\n", f.RatedDate.Format("2006-01-02"), formatDays(*f.DaysToRated))
+		} else {
+			fmt.Println("Rated Reached: n/a")
+		}
+		if f.WearPercent != nil {
+			fmt.Printf("Wear:          %.0f%%\n", *f.WearPercent)
+		}
+		if f.WearPerDay != nil {
+			fmt.Printf("Wear Rate:     %.3f%%/day\n", *f.WearPerDay)
+		}
+		if f.DaysToFullWear != nil {
+			fmt.Printf("100%% Wear:     %s (%s)\n", f.FullWearDate.Format("2006-01-02"), formatDays(*f.DaysToFullWear))
+		} else {
+			fmt.Println("100% Wear:     n/a")
+		}
 		return nil
 	},
 }
 
+// formatDays formats a day count, switching to years past a year.
+func formatDays(days float64) string {
+	if days >= 365 {
+		return fmt.Sprintf("%.1f years", days/365)
+	}
+	return fmt.Sprintf("%.0f days", days)
+}
+
 var diskRecordCmd = &cobra.Command{
-	Use:   "record",
+	Use:   "record [device]",
 	Short: "Record a disk health snapshot to history",
 	Long:  `Capture a snapshot of current disk health and append it to the history file.`,
+	Args:  cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		snap, err := disk.RecordSnapshot()
+		devices, err := diskTargets(args, diskAll, false)
 		if err != nil {
-			return fmt.Errorf("failed to record disk snapshot: %w", err)
+			return err
+		}
+
+		var snaps []*disk.HealthSnapshot
+		for _, dev := range devices {
+			snap, err := disk.RecordSnapshot(dev)
+			if err != nil {
+				return fmt.Errorf("failed to record disk snapshot for %s: %w", dev, err)
+			}
+			snaps = append(snaps, snap)
 		}
 
 		if jsonFlag {
-			return printJSON(snap)
+			if !diskAll {
+				return printJSON(snaps[0])
+			}
+			return printJSON(snaps)
 		}
 
-		fmt.Printf("Recorded disk snapshot at %s: %s, SMART=%s, wear=%s\n",
-			snap.Timestamp.Local().Format("2006-01-02 15:04:05"),
-			snap.Model, snap.SmartStatus, snap.WearLevel)
+		for _, snap := range snaps {
+			fmt.Printf("Recorded disk snapshot at %s: %s %s, SMART=%s, wear=%s\n",
+				snap.Timestamp.Local().Format("2006-01-02 15:04:05"),
+				snap.Device, snap.Model, snap.SmartStatus, snap.WearLevel)
+		}
 		return nil
 	},
 }
@@ -132,9 +868,40 @@ var diskRecordCmd = &cobra.Command{
 func init() {
 	diskHistoryCmd.Flags().StringVar(&diskHistoryLast, "last", "", "Show entries from last duration (e.g., 24h, 7d)")
 
+	diskStatusCmd.Flags().BoolVar(&diskStatusSmart, "smart", false, "Include full SMART attributes from smartctl (requires smartmontools)")
+
+	diskUsageCmd.Flags().Float64Var(&diskUsageLow, "low", disk.DefaultLowFreePercent, "Flag containers with less free space than this percentage")
+	diskUsageCmd.Flags().Float64Var(&diskUsageCritical, "critical", disk.DefaultCriticalFreePercent, "Flag containers as critical below this free percentage")
+
+	diskSnapshotsCmd.PersistentFlags().StringVar(&diskSnapshotsVolume, "volume", disk.DefaultSnapshotVolume, "Volume whose local snapshots to manage")
+	diskSnapshotsThinCmd.Flags().IntVar(&diskThinKeep, "keep", 0, "Keep only the newest N snapshots")
+	diskSnapshotsThinCmd.Flags().StringVar(&diskThinFree, "free", "", "Thin snapshots until this much space is reclaimed (e.g., 20G)")
+	diskSnapshotsThinCmd.Flags().BoolVar(&diskThinDryRun, "dry-run", false, "Show what would be deleted without deleting")
+	diskSnapshotsThinCmd.Flags().BoolVarP(&diskThinYes, "yes", "y", false, "Do not ask for confirmation")
+	diskSnapshotsCmd.AddCommand(diskSnapshotsThinCmd)
+
+	diskEjectCmd.Flags().BoolVarP(&diskForce, "force", "f", false, "Unmount even if volumes are in use")
+	diskUnmountCmd.Flags().BoolVarP(&diskForce, "force", "f", false, "Unmount even if the volume is in use")
+
+	diskForecastCmd.Flags().Float64Var(&diskForecastTBW, "tbw", 0, "Rated write endurance in TB written (default: estimated from capacity)")
+
+	diskIOCmd.Flags().BoolVarP(&diskIOWatch, "watch", "w", false, "Keep sampling until interrupted, then print a summary")
+	diskIOCmd.Flags().StringVar(&diskIOInterval, "interval", disk.DefaultIOInterval.String(), "Time between counter samples (e.g., 1s, 500ms)")
+
+	for _, c := range []*cobra.Command{diskStatusCmd, diskIOCmd, diskRecordCmd} {
+		c.Flags().BoolVar(&diskAll, "all", false, "Act on every disk")
+	}
+
+	diskCmd.AddCommand(diskListCmd)
 	diskCmd.AddCommand(diskStatusCmd)
+	diskCmd.AddCommand(diskUsageCmd)
+	diskCmd.AddCommand(diskSnapshotsCmd)
+	diskCmd.AddCommand(diskMountCmd)
+	diskCmd.AddCommand(diskUnmountCmd)
+	diskCmd.AddCommand(diskEjectCmd)
 	diskCmd.AddCommand(diskIOCmd)
 	diskCmd.AddCommand(diskHistoryCmd)
 	diskCmd.AddCommand(diskRecordCmd)
+	diskCmd.AddCommand(diskForecastCmd)
 	rootCmd.AddCommand(diskCmd)
 }
@@ -1,78 +1,506 @@
 package cli
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
 	"text/tabwriter"
 	"time"
 
 	"github.com/spf13/cobra"
 
 	"github.com/lu-zhengda/macctl/internal/events"
+	"github.com/lu-zhengda/macctl/internal/power"
 )
 
 var eventsLast string
+var eventsSince string
+var eventsUntil string
 var typeFilter string
+var eventsSource string
+var eventsFollow bool
+var eventsDedup string
+var eventsNoDedup bool
+var eventsDedupBy string
 
 var eventsCmd = &cobra.Command{
 	Use:   "events",
-	Short: "Show power events from system log",
-	Long: `Query the macOS system log for power-related events such as
-wake/sleep, lid open/close, thermal throttling, and power source changes.`,
+	Short: "Show system events from the unified log",
+	Long: `Query the macOS system log for events such as wake/sleep, lid open/close,
+thermal throttling, and power source changes.
+
+Use --source to select other event sources: display connect/disconnect,
+audio device changes, Bluetooth connections, Wi-Fi association changes and
+Focus mode changes.
+
+Events saved by 'macctl events archive' are merged with the live log, so
+windows longer than the log's retention return complete results.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		duration := eventsLast
-		if duration == "" {
-			duration = "24h"
+		classifier, err := eventsClassifier()
+		if err != nil {
+			return err
 		}
 
-		powerEvents, err := events.GetEvents(duration)
+		dedup, err := eventsDedupOptions()
 		if err != nil {
-			return fmt.Errorf("failed to get power events: %w", err)
+			return err
 		}
 
-		if typeFilter != "" {
-			var filtered []events.PowerEvent
-			for _, e := range powerEvents {
-				if e.Type == typeFilter {
-					filtered = append(filtered, e)
-				}
+		if eventsFollow {
+			return followEvents(classifier)
+		}
+
+		q, window, err := eventsQuery(eventsLast, eventsSince, eventsUntil)
+		if err != nil {
+			return err
+		}
+		q.Classifier = classifier
+
+		all, err := events.CollectArchivedEvents(q)
+		if err = warnArchive(err); err != nil {
+			return fmt.Errorf("failed to get events: %w", err)
+		}
+
+		var found []events.Event
+		for _, e := range all {
+			if typeFilter == "" || e.Type == typeFilter {
+				found = append(found, e)
 			}
-			powerEvents = filtered
 		}
 
-		powerEvents = events.DeduplicateEvents(powerEvents, 30*time.Second)
+		if !eventsNoDedup {
+			found = events.Deduplicate(found, dedup)
+		}
 
 		if jsonFlag {
-			return printJSON(powerEvents)
+			return printJSON(found)
 		}
 
-		if len(powerEvents) == 0 {
-			fmt.Printf("No power events found in the last %s.\n", duration)
+		if len(found) == 0 {
+			fmt.Printf("No events found %s.\n", window)
 			return nil
 		}
 
 		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
-		fmt.Fprintln(w, "TIMESTAMP\tTYPE\tDETAIL")
-		for _, e := range powerEvents {
+		fmt.Fprintln(w, "TIMESTAMP\tSOURCE\tTYPE\tDETAIL")
+		for _, e := range found {
 			detail := e.Detail
 			if len(detail) > 80 {
 				detail = detail[:80] + "..."
 			}
 			typeStr := e.Type
 			if e.Count > 1 {
-				typeStr = fmt.Sprintf("%s (x%d)", e.Type, e.Count)
+				typeStr = fmt.Sprintf("%s (x%d over %s)", e.Type, e.Count, e.LastSeen.Sub(e.FirstSeen).Round(time.Second))
 			}
-			fmt.Fprintf(w, "%s\t%s\t%s\n",
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n",
 				e.Timestamp.Local().Format("2006-01-02 15:04:05"),
-				typeStr, detail)
+				e.Source, typeStr, detail)
+		}
+		w.Flush()
+		return nil
+	},
+}
+
+// warnArchive prints an unreadable-archive error from events.GetEvents or
+// events.CollectArchivedEvents as a warning and drops it; the events were
+// still read from the system log. Other errors are returned unchanged.
+func warnArchive(err error) error {
+	var archErr *events.ArchiveError
+	if errors.As(err, &archErr) {
+		fmt.Fprintf(os.Stderr, "warning: %v\n", archErr)
+		return nil
+	}
+	return err
+}
+
+// eventsDedupOptions builds dedup options from --dedup and --dedup-by.
+func eventsDedupOptions() (events.DedupOptions, error) {
+	window, err := events.ParseDedupWindow(eventsDedup)
+	if err != nil {
+		return events.DedupOptions{}, fmt.Errorf("invalid --dedup: %w", err)
+	}
+
+	by, err := events.ParseDedupKey(eventsDedupBy)
+	if err != nil {
+		return events.DedupOptions{}, err
+	}
+	return events.DedupOptions{Window: window, By: by}, nil
+}
+
+// eventsQuery builds a log query from --last or --since/--until, along with
+// a description of the window for messages.
+func eventsQuery(last, since, until string) (events.Query, string, error) {
+	if since == "" && until == "" {
+		if last == "" {
+			last = "24h"
+		}
+		dur, err := events.ParseDuration(last)
+		if err != nil {
+			return events.Query{}, "", fmt.Errorf("invalid duration: %w", err)
+		}
+		return events.LastQuery(dur), "in the last " + last, nil
+	}
+
+	if last != "" {
+		return events.Query{}, "", fmt.Errorf("--last cannot be combined with --since or --until")
+	}
+
+	now := time.Now()
+	q := events.Query{Until: now}
+	if until != "" {
+		t, err := events.ParseTime(until, now)
+		if err != nil {
+			return events.Query{}, "", fmt.Errorf("invalid --until: %w", err)
+		}
+		q.Until = t
+	}
+	if since != "" {
+		t, err := events.ParseTime(since, now)
+		if err != nil {
+			return events.Query{}, "", fmt.Errorf("invalid --since: %w", err)
+		}
+		q.Since = t
+	} else {
+		q.Since = q.Until.Add(-24 * time.Hour)
+	}
+
+	window := fmt.Sprintf("between %s and %s",
+		q.Since.Local().Format("2006-01-02 15:04"), q.Until.Local().Format("2006-01-02 15:04"))
+	return q, window, nil
+}
+
+var eventsWakesLast string
+
+var eventsWakesCmd = &cobra.Command{
+	Use:   "wakes",
+	Short: "Summarize what woke the Mac",
+	Long: `Count wake events by normalized reason (lid, power button, RTC/maintenance,
+network, USB/HID, DarkWake) overall and per night.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		powerEvents, err := events.GetEvents(eventsWakesLast)
+		if err = warnArchive(err); err != nil {
+			return fmt.Errorf("failed to get power events: %w", err)
+		}
+
+		// Every wake counts, so the events are not deduplicated first.
+		report := events.SummarizeWakes(powerEvents)
+
+		if jsonFlag {
+			return printJSON(report)
+		}
+
+		if report.Total == 0 {
+			fmt.Printf("No wake events found in the last %s.\n", eventsWakesLast)
+			return nil
+		}
+
+		fmt.Printf("Wakes in the last %s: %d\n\n", eventsWakesLast, report.Total)
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "CATEGORY\tCOUNT")
+		for _, c := range report.ByCategory {
+			fmt.Fprintf(w, "%s\t%d\n", c.Reason, c.Count)
+		}
+		w.Flush()
+		fmt.Println()
+
+		w = tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "NIGHT\tWAKES\tBREAKDOWN")
+		for _, n := range report.Nights {
+			var parts []string
+			for _, c := range report.ByCategory {
+				if count := n.Categories[c.Reason]; count > 0 {
+					parts = append(parts, fmt.Sprintf("%s=%d", c.Reason, count))
+				}
+			}
+			fmt.Fprintf(w, "%s\t%d\t%s\n", n.Night, n.Total, strings.Join(parts, " "))
+		}
+		w.Flush()
+		return nil
+	},
+}
+
+var eventsSummaryLast string
+
+var eventsSummaryCmd = &cobra.Command{
+	Use:   "summary",
+	Short: "Summarize events per day with hour-of-day heatmaps",
+	Long: `Count events per day and type, and chart wakes and thermal events on an
+hour-of-day by day heatmap. Darker cells mean more events in that hour.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dur, err := events.ParseDuration(eventsSummaryLast)
+		if err != nil {
+			return fmt.Errorf("invalid duration: %w", err)
+		}
+		until := time.Now()
+		since := until.Add(-dur)
+
+		found, err := events.GetEvents(eventsSummaryLast)
+		if err = warnArchive(err); err != nil {
+			return fmt.Errorf("failed to get events: %w", err)
+		}
+		// Every event counts, so the events are not deduplicated first.
+		summary := events.Summarize(found, since, until, events.HeatmapTypes)
+
+		if jsonFlag {
+			return printJSON(summary)
+		}
+
+		if summary.Total == 0 {
+			fmt.Printf("No events found in the last %s.\n", eventsSummaryLast)
+			return nil
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		header := []string{"DATE", "TOTAL"}
+		for _, t := range summary.Types {
+			header = append(header, strings.ToUpper(t.Type))
+		}
+		fmt.Fprintln(w, strings.Join(header, "\t"))
+		for _, d := range summary.Days {
+			row := []string{d.Date, strconv.Itoa(d.Total)}
+			for _, t := range summary.Types {
+				row = append(row, strconv.Itoa(d.Types[t.Type]))
+			}
+			fmt.Fprintln(w, strings.Join(row, "\t"))
+		}
+		w.Flush()
+
+		for _, h := range summary.Heatmaps {
+			fmt.Printf("\n%s by hour (%d total, max %d/hour)\n", h.Type, h.Total, h.Max)
+			fmt.Printf("%-10s  %s\n", "", "000000000011111111112222")
+			fmt.Printf("%-10s  %s\n", "", "012345678901234567890123")
+			for _, d := range h.Days {
+				var row strings.Builder
+				for _, n := range d.Hours {
+					row.WriteByte(heatChar(n, h.Max))
+				}
+				fmt.Printf("%-10s  %s\n", d.Date, row.String())
+			}
+		}
+		return nil
+	},
+}
+
+// heatLevels shades heatmap cells from empty to busiest.
+const heatLevels = " .:*#"
+
+// heatChar returns the heatmap shade for n events given the busiest cell.
+func heatChar(n, max int) byte {
+	if n <= 0 || max <= 0 {
+		return heatLevels[0]
+	}
+	steps := len(heatLevels) - 1
+	level := (n*steps + max - 1) / max
+	return heatLevels[level]
+}
+
+var eventsSleepLast string
+
+var eventsSleepReportCmd = &cobra.Command{
+	Use:   "sleep-report",
+	Short: "Show sleep intervals with DarkWakes and battery drain",
+	Long: `Pair sleep and wake events into sleep intervals, showing each interval's
+duration, the number of DarkWakes inside it, and the battery percentage drop
+from recorded power history.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		powerEvents, err := events.GetEvents(eventsSleepLast)
+		if err = warnArchive(err); err != nil {
+			return fmt.Errorf("failed to get power events: %w", err)
+		}
+
+		sessions := events.PairSleepSessions(powerEvents)
+
+		snapshots, err := power.LoadHistory()
+		if err != nil {
+			return fmt.Errorf("failed to load power history: %w", err)
+		}
+		events.AttachBattery(sessions, snapshots, events.DefaultBatteryTolerance)
+
+		if jsonFlag {
+			return printJSON(sessions)
+		}
+
+		if len(sessions) == 0 {
+			fmt.Printf("No sleep intervals found in the last %s.\n", eventsSleepLast)
+			return nil
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "START\tEND\tDURATION\tDARKWAKES\tBATTERY\tWOKE_BY")
+		for _, s := range sessions {
+			battery := "-"
+			if s.BatteryDrop != nil {
+				// A negative drop means the battery charged during sleep.
+				battery = fmt.Sprintf("%d%% -> %d%% (%+d%%)", *s.BatteryStart, *s.BatteryEnd, -*s.BatteryDrop)
+			}
+			wokeBy := s.WakeCategory
+			if wokeBy == "" {
+				wokeBy = "-"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%s\t%s\n",
+				s.Start.Local().Format("2006-01-02 15:04"),
+				s.End.Local().Format("2006-01-02 15:04"),
+				s.Duration().Round(time.Minute), s.DarkWakes, battery, wokeBy)
+		}
+		w.Flush()
+		return nil
+	},
+}
+
+// eventsClassifier loads user event rules for the --source sources and
+// checks --type against the event types they can produce.
+func eventsClassifier() (*events.Classifier, error) {
+	sources, err := events.ParseSources(eventsSource)
+	if err != nil {
+		return nil, err
+	}
+
+	classifier, err := events.LoadClassifier(sources)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load event rules: %w", err)
+	}
+
+	if typeFilter != "" && !classifier.HasType(typeFilter) {
+		return nil, fmt.Errorf("unknown event type %q (known types: %s)",
+			typeFilter, strings.Join(classifier.Types(), ", "))
+	}
+	return classifier, nil
+}
+
+var eventsRulesCmd = &cobra.Command{
+	Use:   "rules",
+	Short: "List user-defined event classification rules",
+	Long: `List event classification rules from ~/.config/macctl/event-rules.json.
+
+Each rule turns matching log entries into events of its own type, which
+'macctl events --type' accepts. Example file:
+
+  {
+    "rules": [
+      {
+        "name": "usb_attach",
+        "process": "kernel",
+        "match": "USB device attached: (?P<device>.+)"
+      }
+    ]
+  }`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		classifier, err := events.LoadClassifier(nil)
+		if err != nil {
+			return fmt.Errorf("failed to load event rules: %w", err)
+		}
+		rules := classifier.Rules()
+
+		if jsonFlag {
+			return printJSON(rules)
+		}
+
+		if len(rules) == 0 {
+			path, _ := events.RulesPath()
+			fmt.Printf("No event rules configured. Add rules to %s.\n", path)
+			return nil
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "NAME\tSUBSYSTEM\tPROCESS\tMATCH")
+		for _, r := range rules {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", r.Name, orDash(r.Subsystem), orDash(r.Process), r.Match)
 		}
 		w.Flush()
 		return nil
 	},
 }
 
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+var eventsArchiveCmd = &cobra.Command{
+	Use:   "archive",
+	Short: "Persist new events to the local archive",
+	Long: `Classify events from every source logged since the last archive run and
+append them to ~/.config/macctl/events-archive.json. The unified log only
+keeps a few days of history; the archive keeps events for a year.
+
+The background recorder runs this with the "events" domain.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		result, err := events.UpdateArchive()
+		if err != nil {
+			return fmt.Errorf("failed to archive events: %w", err)
+		}
+
+		if jsonFlag {
+			return printJSON(result)
+		}
+
+		if result.Corrupt != "" {
+			fmt.Printf("The events archive was corrupt; moved it to %s and started a new one.\n", result.Corrupt)
+		}
+		fmt.Printf("Archived %d new events since %s (%d total).\n",
+			result.Added, result.Since.Local().Format("2006-01-02 15:04"), result.Total)
+		return nil
+	},
+}
+
+// followEvents prints events as they arrive until interrupted.
+func followEvents(classifier *events.Classifier) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if !jsonFlag {
+		fmt.Printf("%-19s  %-9s  %-22s  %s\n", "TIMESTAMP", "SOURCE", "TYPE", "DETAIL")
+	}
+
+	return events.Follow(ctx, classifier, func(e events.Event) {
+		if typeFilter != "" && e.Type != typeFilter {
+			return
+		}
+
+		if jsonFlag {
+			printNDJSON(e)
+			return
+		}
+
+		detail := e.Detail
+		if len(detail) > 80 {
+			detail = detail[:80] + "..."
+		}
+		fmt.Printf("%-19s  %-9s  %-22s  %s\n",
+			e.Timestamp.Local().Format("2006-01-02 15:04:05"), e.Source, e.Type, detail)
+	}, func(err error, delay time.Duration) {
+		fmt.Fprintf(os.Stderr, "log stream stopped (%v); reconnecting in %s\n", err, delay)
+	})
+}
+
 func init() {
 	eventsCmd.Flags().StringVar(&eventsLast, "last", "", "Duration to look back (e.g., 24h, 7d; default: 24h)")
-	eventsCmd.Flags().StringVar(&typeFilter, "type", "", "Filter events by type (e.g., wake, sleep, power_source_change)")
+	eventsCmd.Flags().StringVar(&eventsSince, "since", "", "Start of the time range (e.g., 2025-01-15, \"yesterday 22:00\", RFC3339)")
+	eventsCmd.Flags().StringVar(&eventsUntil, "until", "", "End of the time range (default: now)")
+	eventsCmd.Flags().BoolVarP(&eventsFollow, "follow", "f", false, "Stream new events as they happen")
+	eventsCmd.Flags().StringVar(&eventsDedup, "dedup", events.DefaultDedupWindow.String(), "Collapse repeated events within this window (e.g., 30s, 5m)")
+	eventsCmd.Flags().BoolVar(&eventsNoDedup, "no-dedup", false, "Show every event without collapsing repeats")
+	eventsCmd.Flags().StringVar(&eventsDedupBy, "dedup-by", events.DedupByType, "Treat events as repeats by type or type+reason")
+	eventsCmd.Flags().StringVar(&eventsSource, "source", "", "Comma-separated event sources: power, display, audio, bluetooth, wifi, focus, or all (default: power)")
+	eventsCmd.Flags().StringVar(&typeFilter, "type", "", "Filter events by type (e.g., wake, sleep, power_source_change, or a custom rule name)")
+	eventsWakesCmd.Flags().StringVar(&eventsWakesLast, "last", "7d", "Duration to look back (e.g., 24h, 7d)")
+
+	eventsSummaryCmd.Flags().StringVar(&eventsSummaryLast, "last", "30d", "Duration to look back (e.g., 7d, 30d)")
+
+	eventsSleepReportCmd.Flags().StringVar(&eventsSleepLast, "last", "7d", "Duration to look back (e.g., 24h, 7d)")
+
+	eventsCmd.AddCommand(eventsWakesCmd)
+	eventsCmd.AddCommand(eventsSleepReportCmd)
+	eventsCmd.AddCommand(eventsSummaryCmd)
+	eventsCmd.AddCommand(eventsRulesCmd)
+	eventsCmd.AddCommand(eventsArchiveCmd)
 	rootCmd.AddCommand(eventsCmd)
 }
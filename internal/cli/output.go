@@ -1,9 +1,11 @@
 package cli
 
 import (
+	"bufio"
 	"encoding/json"
 	"fmt"
 	"os"
+	"strings"
 )
 
 func printJSON(v any) error {
@@ -14,3 +16,23 @@ func printJSON(v any) error {
 	}
 	return nil
 }
+
+// printNDJSON writes v as a single compact JSON line, for streaming output.
+func printNDJSON(v any) error {
+	if err := json.NewEncoder(os.Stdout).Encode(v); err != nil {
+		return fmt.Errorf("failed to encode JSON: %w", err)
+	}
+	return nil
+}
+
+// confirm asks a yes/no question on stderr and reports whether the user
+// answered yes. Anything but "y" or "yes", including EOF, is a no.
+func confirm(prompt string) bool {
+	fmt.Fprintf(os.Stderr, "%s [y/N]: ", prompt)
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true
+	}
+	return false
+}
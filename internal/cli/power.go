@@ -29,6 +29,19 @@ var powerStatusCmd = &cobra.Command{
 			return printJSON(s)
 		}
 
+		if !s.HasBattery {
+			source := s.PowerSource
+			if source == "" {
+				source = "AC Power"
+			}
+			fmt.Printf("Battery:       none\n")
+			fmt.Printf("Power Source:  %s\n", source)
+			for _, src := range s.Sources {
+				fmt.Printf("%-14s %d%%, %s, %s\n", src.Name+":", src.Percent, src.State, src.TimeRemaining)
+			}
+			return nil
+		}
+
 		chargingState := "discharging"
 		if s.IsCharging {
 			chargingState = "charging"
@@ -46,6 +59,40 @@ var powerStatusCmd = &cobra.Command{
 	},
 }
 
+var powerSourcesCmd = &cobra.Command{
+	Use:   "sources",
+	Short: "List power sources (battery, UPS)",
+	Long:  `List every power source macOS reports, including the internal battery, UPSes, and external batteries.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		info, err := power.GetSources()
+		if err != nil {
+			return fmt.Errorf("failed to get power sources: %w", err)
+		}
+
+		if jsonFlag {
+			return printJSON(info)
+		}
+
+		if info.DrawingFrom != "" {
+			fmt.Printf("Drawing from: %s\n\n", info.DrawingFrom)
+		}
+
+		if len(info.Sources) == 0 {
+			fmt.Println("No battery or UPS power sources found.")
+			return nil
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "NAME\tTYPE\tCHARGE\tSTATE\tREMAINING")
+		for _, src := range info.Sources {
+			fmt.Fprintf(w, "%s\t%s\t%d%%\t%s\t%s\n",
+				src.Name, src.Type, src.Percent, src.State, src.TimeRemaining)
+		}
+		w.Flush()
+		return nil
+	},
+}
+
 var powerHealthCmd = &cobra.Command{
 	Use:   "health",
 	Short: "Show battery health",
@@ -116,8 +163,9 @@ var powerAssertionsCmd = &cobra.Command{
 }
 
 var (
-	powerHogsN       int
-	powerHistoryLast string
+	powerHogsN              int
+	powerHistoryLast        string
+	powerHistoryImportPmset bool
 )
 
 var powerHistoryCmd = &cobra.Command{
@@ -171,6 +219,34 @@ var powerHistoryCmd = &cobra.Command{
 	},
 }
 
+var powerHistoryImportCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Backfill power history from system logs",
+	Long: `Import past battery readings into the history file so forecasts and
+sessions have data from day one. Entries already in the history are skipped.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if !powerHistoryImportPmset {
+			return fmt.Errorf("no import source given (use --from-pmset)")
+		}
+
+		added, err := power.ImportPmsetLog()
+		if err != nil {
+			return fmt.Errorf("failed to import pmset log: %w", err)
+		}
+
+		if jsonFlag {
+			return printJSON(map[string]int{"imported": added})
+		}
+
+		if added == 0 {
+			fmt.Println("No new battery readings found in the pmset log.")
+			return nil
+		}
+		fmt.Printf("Imported %d snapshots from the pmset log.\n", added)
+		return nil
+	},
+}
+
 var powerRecordCmd = &cobra.Command{
 	Use:   "record",
 	Short: "Record a power snapshot to history",
@@ -192,6 +268,73 @@ var powerRecordCmd = &cobra.Command{
 	},
 }
 
+var (
+	powerEnergyLast   string
+	powerEnergyPrice  float64
+	powerEnergyCarbon float64
+)
+
+var powerEnergyCmd = &cobra.Command{
+	Use:   "energy",
+	Short: "Show energy drawn from the wall and battery per day",
+	Long: `Estimate watt-hours drawn from the wall and from the battery per day,
+using voltage and amperage recorded in power history. Optionally estimate
+electricity cost and carbon emissions from the wall energy.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dur, err := power.ParseDuration(powerEnergyLast)
+		if err != nil {
+			return fmt.Errorf("invalid duration: %w", err)
+		}
+
+		snapshots, err := power.LoadHistory()
+		if err != nil {
+			return fmt.Errorf("failed to load power history: %w", err)
+		}
+		snapshots = power.FilterHistory(snapshots, dur)
+
+		report := power.ComputeEnergy(snapshots, power.EnergyOptions{
+			PricePerKWh:       powerEnergyPrice,
+			CarbonGramsPerKWh: powerEnergyCarbon,
+		})
+
+		if jsonFlag {
+			return printJSON(report)
+		}
+
+		if len(report.Days) == 0 {
+			fmt.Println("Not enough power history with voltage readings. Use 'macctl record' to capture snapshots.")
+			return nil
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		header := "DATE\tWALL\tBATTERY"
+		if powerEnergyPrice > 0 {
+			header += "\tCOST"
+		}
+		if powerEnergyCarbon > 0 {
+			header += "\tCO2"
+		}
+		fmt.Fprintln(w, header)
+
+		row := func(label string, wall, batt, cost, carbon float64) {
+			line := fmt.Sprintf("%s\t%.1f Wh\t%.1f Wh", label, wall, batt)
+			if powerEnergyPrice > 0 {
+				line += fmt.Sprintf("\t%.3f", cost)
+			}
+			if powerEnergyCarbon > 0 {
+				line += fmt.Sprintf("\t%.0f g", carbon)
+			}
+			fmt.Fprintln(w, line)
+		}
+		for _, d := range report.Days {
+			row(d.Date, d.WallWh, d.BatteryWh, d.Cost, d.CarbonGrams)
+		}
+		row("TOTAL", report.TotalWallWh, report.TotalBatteryWh, report.TotalCost, report.TotalCarbonGrams)
+		w.Flush()
+		return nil
+	},
+}
+
 var powerHogsCmd = &cobra.Command{
 	Use:   "hogs",
 	Short: "Show top energy consumers",
@@ -223,13 +366,20 @@ var powerHogsCmd = &cobra.Command{
 func init() {
 	powerHogsCmd.Flags().IntVarP(&powerHogsN, "n", "n", 5, "Number of processes to show")
 	powerHistoryCmd.Flags().StringVar(&powerHistoryLast, "last", "", "Show entries from last duration (e.g., 24h, 7d)")
+	powerHistoryImportCmd.Flags().BoolVar(&powerHistoryImportPmset, "from-pmset", false, "Import battery readings from 'pmset -g log'")
+	powerHistoryCmd.AddCommand(powerHistoryImportCmd)
+	powerEnergyCmd.Flags().StringVar(&powerEnergyLast, "last", "7d", "Duration to account for (e.g., 24h, 7d)")
+	powerEnergyCmd.Flags().Float64Var(&powerEnergyPrice, "price", 0, "Electricity price per kWh for cost estimates")
+	powerEnergyCmd.Flags().Float64Var(&powerEnergyCarbon, "carbon", 0, "Grid carbon intensity in grams CO2 per kWh")
 
 	powerCmd.AddCommand(powerStatusCmd)
+	powerCmd.AddCommand(powerSourcesCmd)
 	powerCmd.AddCommand(powerHealthCmd)
 	powerCmd.AddCommand(powerThermalCmd)
 	powerCmd.AddCommand(powerAssertionsCmd)
 	powerCmd.AddCommand(powerHogsCmd)
 	powerCmd.AddCommand(powerHistoryCmd)
 	powerCmd.AddCommand(powerRecordCmd)
+	powerCmd.AddCommand(powerEnergyCmd)
 	rootCmd.AddCommand(powerCmd)
 }
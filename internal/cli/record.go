@@ -0,0 +1,72 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/spf13/cobra"
+
+	"github.com/lu-zhengda/macctl/internal/power"
+	"github.com/lu-zhengda/macctl/internal/recorder"
+)
+
+var (
+	recordEvery   string
+	recordDomains string
+)
+
+var recordCmd = &cobra.Command{
+	Use:   "record",
+	Short: "Continuously record history snapshots in the background",
+	Long: `Run a long-lived loop that records power, disk, and thermal snapshots
+and archives system events on a fixed interval, keeping all histories fresh
+from a single process.
+
+Each round is delayed by a small random jitter. A domain that keeps failing
+is retried with exponential backoff. The loop exits cleanly on SIGINT or SIGTERM.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		interval, err := power.ParseDuration(recordEvery)
+		if err != nil {
+			return fmt.Errorf("invalid interval: %w", err)
+		}
+
+		domains, err := recorder.ParseDomains(recordDomains)
+		if err != nil {
+			return err
+		}
+
+		cfg := recorder.Config{
+			Interval:   interval,
+			Jitter:     interval / 10,
+			MaxBackoff: recorder.DefaultMaxBackoff,
+			Tasks:      recorder.Tasks(domains),
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		return recorder.Run(ctx, cfg, func(r recorder.Result) {
+			if jsonFlag {
+				printNDJSON(r)
+				return
+			}
+
+			ts := r.Timestamp.Local().Format("2006-01-02 15:04:05")
+			if r.Success {
+				fmt.Printf("%s  %-7s recorded\n", ts, r.Domain)
+				return
+			}
+			fmt.Fprintf(os.Stderr, "%s  %-7s failed (%d in a row): %s; next attempt at %s\n",
+				ts, r.Domain, r.Failures, r.Error, r.NextRun.Local().Format("15:04:05"))
+		})
+	},
+}
+
+func init() {
+	recordCmd.Flags().StringVar(&recordEvery, "every", recorder.DefaultInterval, "Recording interval (e.g., 5m, 1h)")
+	recordCmd.Flags().StringVar(&recordDomains, "domains", "power,disk,thermal,events", "Comma-separated domains to record (power, disk, thermal, events)")
+	rootCmd.AddCommand(recordCmd)
+}
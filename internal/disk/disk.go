@@ -6,7 +6,6 @@ import (
 	"os/exec"
 	"regexp"
 	"strconv"
-	"strings"
 )
 
 // Health holds SSD health information.
@@ -19,24 +18,28 @@ type Health struct {
 	WearLevel   string `json:"wear_level"`
 	DataWritten string `json:"data_written"`
 	SmartStatus string `json:"smart_status"`
-}
+	Info        *Info  `json:"info,omitempty"`
+
+	// Smart is set only when requested and smartctl is installed.
+	Smart *SmartAttributes `json:"smart,omitempty"`
 
-// IOStats holds current I/O rate information.
-type IOStats struct {
-	ReadMBs   float64 `json:"read_mbs"`
-	WriteMBs  float64 `json:"write_mbs"`
-	ReadIOPS  float64 `json:"read_iops"`
-	WriteIOPS float64 `json:"write_iops"`
+	// SmartError explains why Smart is missing when reading it failed for
+	// this disk alone.
+	SmartError string `json:"smart_error,omitempty"`
 }
 
-// GetHealth returns disk health information for disk0.
-func GetHealth() (*Health, error) {
-	diskutilOut, err := exec.Command("diskutil", "info", "disk0").Output()
+// GetHealth returns disk health information for a device such as "disk0".
+func GetHealth(device string) (*Health, error) {
+	device = NormalizeDevice(device)
+
+	info, err := GetInfo(device)
 	if err != nil {
-		return nil, fmt.Errorf("failed to run diskutil: %w", err)
+		return nil, fmt.Errorf("failed to get disk info for %s: %w", device, err)
+	}
+	h := healthFromInfo(info)
+	if h.Device == "" {
+		h.Device = device
 	}
-
-	h := parseDiskutilInfo(string(diskutilOut))
 
 	// Try to get NVMe-specific data.
 	nvmeOut, err := exec.Command("system_profiler", "SPNVMeDataType", "-json").Output()
@@ -47,52 +50,15 @@ func GetHealth() (*Health, error) {
 	return h, nil
 }
 
-// GetIOStats returns current disk I/O rates by running iostat with two samples.
-func GetIOStats() (*IOStats, error) {
-	// Take 2 samples at 1-second interval; the second sample gives accurate rates.
-	out, err := exec.Command("iostat", "-d", "-c", "2", "-w", "1").Output()
-	if err != nil {
-		return nil, fmt.Errorf("failed to run iostat: %w", err)
-	}
-
-	return parseIOStat(string(out))
-}
-
-func parseDiskutilInfo(output string) *Health {
-	h := &Health{
-		Device:      "disk0",
-		SmartStatus: "unknown",
-		WearLevel:   "unavailable",
-		DataWritten: "unavailable",
-	}
-
-	for _, line := range strings.Split(output, "\n") {
-		line = strings.TrimSpace(line)
-		if line == "" {
-			continue
-		}
+// runDiskutilInfo runs `diskutil info -plist`; tests replace it.
+var runDiskutilInfo = execDiskutilInfo
 
-		parts := strings.SplitN(line, ":", 2)
-		if len(parts) != 2 {
-			continue
-		}
-		key := strings.TrimSpace(parts[0])
-		val := strings.TrimSpace(parts[1])
-
-		switch key {
-		case "Device / Media Name":
-			h.Model = val
-		case "Protocol":
-			h.Protocol = val
-		case "Disk Size":
-			h.SizeHuman = val
-			h.SizeBytes = parseSizeBytes(val)
-		case "SMART Status":
-			h.SmartStatus = val
-		}
+func execDiskutilInfo(device string) ([]byte, error) {
+	out, err := exec.Command("diskutil", "info", "-plist", device).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to run diskutil info %s: %w", device, err)
 	}
-
-	return h
+	return out, nil
 }
 
 func parseSizeBytes(s string) int64 {
@@ -115,12 +81,13 @@ type nvmeProfiler struct {
 }
 
 type nvmeItem struct {
-	DeviceName         string `json:"_name"`
-	DeviceModel        string `json:"device_model"`
-	WearLevelCount     string `json:"spnvme_wearleveling"`
-	DataBytesWritten   string `json:"spnvme_byteswritten"`
-	DataBytesRead      string `json:"spnvme_bytesread"`
-	SmartHealthStatus  string `json:"spnvme_smart_status"`
+	DeviceName        string `json:"_name"`
+	BSDName           string `json:"bsd_name"`
+	DeviceModel       string `json:"device_model"`
+	WearLevelCount    string `json:"spnvme_wearleveling"`
+	DataBytesWritten  string `json:"spnvme_byteswritten"`
+	DataBytesRead     string `json:"spnvme_bytesread"`
+	SmartHealthStatus string `json:"spnvme_smart_status"`
 }
 
 func enrichWithNVMe(h *Health, data []byte) {
@@ -129,89 +96,43 @@ func enrichWithNVMe(h *Health, data []byte) {
 		return
 	}
 
-	for _, group := range sp.SPNVMeDataType {
-		for _, item := range group.Items {
-			// Use the first NVMe device found (usually the primary SSD).
-			if item.DeviceModel != "" && h.Model == "" {
-				h.Model = item.DeviceModel
-			}
-			if item.WearLevelCount != "" {
-				h.WearLevel = item.WearLevelCount
-			}
-			if item.DataBytesWritten != "" {
-				h.DataWritten = item.DataBytesWritten
-			}
-			if item.SmartHealthStatus != "" {
-				h.SmartStatus = item.SmartHealthStatus
-			}
-			return // Use first device.
-		}
+	item := matchNVMe(sp, h.Device)
+	if item == nil {
+		return
 	}
-}
 
-func parseIOStat(output string) (*IOStats, error) {
-	lines := strings.Split(strings.TrimSpace(output), "\n")
-
-	// iostat outputs a header block and then data lines. With -c 2, we get
-	// two data sections. We want the last data line (the second sample).
-	// Lines look like:
-	//              disk0
-	//     KB/t  tps  MB/s
-	//    xx.xx  xxx  x.xx
-	//    xx.xx  xxx  x.xx
-
-	var dataLines []string
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if line == "" {
-			continue
-		}
-		// Skip header lines (contain non-numeric first field).
-		fields := strings.Fields(line)
-		if len(fields) == 0 {
-			continue
-		}
-		_, err := strconv.ParseFloat(fields[0], 64)
-		if err == nil {
-			dataLines = append(dataLines, line)
-		}
+	if item.DeviceModel != "" && h.Model == "" {
+		h.Model = item.DeviceModel
 	}
-
-	if len(dataLines) < 2 {
-		// If we have at least one data line, use it.
-		if len(dataLines) == 1 {
-			return parseIOStatLine(dataLines[0])
-		}
-		return nil, fmt.Errorf("insufficient iostat data")
+	if item.WearLevelCount != "" {
+		h.WearLevel = item.WearLevelCount
 	}
-
-	// Use the last data line (second sample).
-	return parseIOStatLine(dataLines[len(dataLines)-1])
-}
-
-func parseIOStatLine(line string) (*IOStats, error) {
-	fields := strings.Fields(line)
-	// Default iostat -d output: KB/t  tps  MB/s
-	if len(fields) < 3 {
-		return nil, fmt.Errorf("unexpected iostat format: %q", line)
+	if item.DataBytesWritten != "" {
+		h.DataWritten = item.DataBytesWritten
 	}
-
-	tps, err := strconv.ParseFloat(fields[1], 64)
-	if err != nil {
-		return nil, fmt.Errorf("failed to parse tps: %w", err)
+	if item.SmartHealthStatus != "" {
+		h.SmartStatus = item.SmartHealthStatus
 	}
+}
 
-	mbs, err := strconv.ParseFloat(fields[2], 64)
-	if err != nil {
-		return nil, fmt.Errorf("failed to parse MB/s: %w", err)
+// matchNVMe returns the NVMe controller for device, matched by BSD name.
+// Older macOS releases omit bsd_name; then the first controller is assumed
+// to be the boot disk.
+func matchNVMe(sp nvmeProfiler, device string) *nvmeItem {
+	var unnamed *nvmeItem
+	for _, group := range sp.SPNVMeDataType {
+		for i := range group.Items {
+			item := &group.Items[i]
+			if item.BSDName == device {
+				return item
+			}
+			if item.BSDName == "" && unnamed == nil {
+				unnamed = item
+			}
+		}
 	}
-
-	// iostat -d without -I gives combined read+write.
-	// We report them as combined since basic iostat doesn't distinguish.
-	return &IOStats{
-		ReadMBs:   mbs,
-		WriteMBs:  0, // iostat -d gives combined, not separate.
-		ReadIOPS:  tps,
-		WriteIOPS: 0,
-	}, nil
+	if unnamed != nil && device == DefaultDevice {
+		return unnamed
+	}
+	return nil
 }
@@ -8,61 +8,6 @@ import (
 	"time"
 )
 
-func TestParseDiskutilInfo(t *testing.T) {
-	input := `   Device Identifier:         disk0
-   Device Node:               /dev/disk0
-   Whole:                     Yes
-   Part of Whole:             disk0
-   Device / Media Name:       APPLE SSD AP0512Q
-   Volume Name:               Not applicable
-   Mounted:                   Not applicable
-   File System:               None
-   Content (IOContent):       GUID_partition_scheme
-   OS Can Be Installed:       No
-   Media Type:                Generic
-   Protocol:                  Apple Fabric
-   SMART Status:              Verified
-   Disk Size:                 500.1 GB (500107862016 Bytes)
-`
-
-	h := parseDiskutilInfo(input)
-
-	if h.Model != "APPLE SSD AP0512Q" {
-		t.Errorf("Model = %q, want %q", h.Model, "APPLE SSD AP0512Q")
-	}
-	if h.Protocol != "Apple Fabric" {
-		t.Errorf("Protocol = %q, want %q", h.Protocol, "Apple Fabric")
-	}
-	if h.SmartStatus != "Verified" {
-		t.Errorf("SmartStatus = %q, want %q", h.SmartStatus, "Verified")
-	}
-	if h.SizeBytes != 500107862016 {
-		t.Errorf("SizeBytes = %d, want %d", h.SizeBytes, 500107862016)
-	}
-	if h.Device != "disk0" {
-		t.Errorf("Device = %q, want %q", h.Device, "disk0")
-	}
-}
-
-func TestParseDiskutilInfoMinimal(t *testing.T) {
-	input := `   Device Identifier:         disk0
-   Protocol:                  NVMe
-   SMART Status:              Not Supported
-`
-
-	h := parseDiskutilInfo(input)
-
-	if h.Protocol != "NVMe" {
-		t.Errorf("Protocol = %q, want %q", h.Protocol, "NVMe")
-	}
-	if h.SmartStatus != "Not Supported" {
-		t.Errorf("SmartStatus = %q, want %q", h.SmartStatus, "Not Supported")
-	}
-	if h.Model != "" {
-		t.Errorf("Model = %q, want empty", h.Model)
-	}
-}
-
 func TestParseSizeBytes(t *testing.T) {
 	tests := []struct {
 		name  string
@@ -138,6 +83,32 @@ func TestEnrichWithNVMe(t *testing.T) {
 	}
 }
 
+func TestEnrichWithNVMeMatchesBSDName(t *testing.T) {
+	nvmeJSON := []byte(`{
+		"SPNVMeDataType": [
+			{
+				"_items": [
+					{"bsd_name": "disk0", "device_model": "APPLE SSD", "spnvme_wearleveling": "1%"},
+					{"bsd_name": "disk4", "device_model": "Samsung SSD 990 PRO", "spnvme_wearleveling": "7%"}
+				]
+			}
+		]
+	}`)
+
+	h := &Health{Device: "disk4", WearLevel: "unavailable"}
+	enrichWithNVMe(h, nvmeJSON)
+	if h.WearLevel != "7%" || h.Model != "Samsung SSD 990 PRO" {
+		t.Errorf("disk4 got wear %q model %q", h.WearLevel, h.Model)
+	}
+
+	// A non-NVMe disk must not pick up another controller's data.
+	h = &Health{Device: "disk6", WearLevel: "unavailable"}
+	enrichWithNVMe(h, nvmeJSON)
+	if h.WearLevel != "unavailable" {
+		t.Errorf("disk6 WearLevel = %q, want unchanged", h.WearLevel)
+	}
+}
+
 func TestEnrichWithNVMeInvalidJSON(t *testing.T) {
 	h := &Health{
 		Device:      "disk0",
@@ -153,94 +124,6 @@ func TestEnrichWithNVMeInvalidJSON(t *testing.T) {
 	}
 }
 
-func TestParseIOStat(t *testing.T) {
-	tests := []struct {
-		name    string
-		input   string
-		wantMBs float64
-		wantTPS float64
-		wantErr bool
-	}{
-		{
-			name: "two samples",
-			input: `              disk0
-    KB/t  tps  MB/s
-   24.00   10  0.23
-   16.00   25  1.50
-`,
-			wantMBs: 1.50,
-			wantTPS: 25,
-		},
-		{
-			name: "single sample",
-			input: `              disk0
-    KB/t  tps  MB/s
-   24.00   10  0.23
-`,
-			wantMBs: 0.23,
-			wantTPS: 10,
-		},
-		{
-			name:    "empty output",
-			input:   "",
-			wantErr: true,
-		},
-		{
-			name: "headers only",
-			input: `              disk0
-    KB/t  tps  MB/s
-`,
-			wantErr: true,
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			got, err := parseIOStat(tt.input)
-			if tt.wantErr {
-				if err == nil {
-					t.Errorf("expected error, got %+v", got)
-				}
-				return
-			}
-			if err != nil {
-				t.Fatalf("unexpected error: %v", err)
-			}
-			if got.ReadMBs != tt.wantMBs {
-				t.Errorf("ReadMBs = %f, want %f", got.ReadMBs, tt.wantMBs)
-			}
-			if got.ReadIOPS != tt.wantTPS {
-				t.Errorf("ReadIOPS = %f, want %f", got.ReadIOPS, tt.wantTPS)
-			}
-		})
-	}
-}
-
-func TestParseIOStatLine(t *testing.T) {
-	tests := []struct {
-		name    string
-		line    string
-		wantErr bool
-	}{
-		{name: "valid", line: "16.00 25 1.50"},
-		{name: "too few fields", line: "16.00", wantErr: true},
-		{name: "invalid tps", line: "16.00 abc 1.50", wantErr: true},
-		{name: "invalid mbs", line: "16.00 25 abc", wantErr: true},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			_, err := parseIOStatLine(tt.line)
-			if tt.wantErr && err == nil {
-				t.Error("expected error")
-			}
-			if !tt.wantErr && err != nil {
-				t.Errorf("unexpected error: %v", err)
-			}
-		})
-	}
-}
-
 func TestDiskHealthJSONRoundTrip(t *testing.T) {
 	h := Health{
 		Device:      "disk0",
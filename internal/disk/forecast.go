@@ -0,0 +1,184 @@
+package disk
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// DefaultTBWPerTB is the endurance assumed when no rating is given: a
+// typical consumer SSD is rated for about 600 TB written per TB of capacity.
+const DefaultTBWPerTB = 600
+
+const day = 24 * time.Hour
+
+// Forecast projects when a disk will reach its rated write endurance and
+// 100% wear, from the write and wear rates seen in history.
+type Forecast struct {
+	Device           string    `json:"device"`
+	Since            time.Time `json:"since"`
+	Until            time.Time `json:"until"`
+	Samples          int       `json:"samples"`
+	DataWrittenBytes int64     `json:"data_written_bytes"`
+	WrittenBytes     int64     `json:"written_bytes"`
+	BytesPerDay      float64   `json:"bytes_per_day"`
+	RatedBytes       int64     `json:"rated_bytes,omitempty"`
+	RatedEstimated   bool      `json:"rated_estimated,omitempty"`
+	DaysToRated      *float64  `json:"days_to_rated,omitempty"`
+	RatedDate        time.Time `json:"rated_date,omitzero"`
+	WearPercent      *float64  `json:"wear_percent,omitempty"`
+	WearPerDay       *float64  `json:"wear_per_day,omitempty"`
+	DaysToFullWear   *float64  `json:"days_to_full_wear,omitempty"`
+	FullWearDate     time.Time `json:"full_wear_date,omitzero"`
+}
+
+// DailyWrite is the number of bytes written to a device on one local day.
+type DailyWrite struct {
+	Date   string `json:"date"`
+	Device string `json:"device"`
+	Bytes  int64  `json:"bytes"`
+}
+
+// snapshotDevice returns the snapshot's device; entries recorded before
+// multi-disk support have none and belong to the default disk.
+func snapshotDevice(s HealthSnapshot) string {
+	if s.Device == "" {
+		return DefaultDevice
+	}
+	return s.Device
+}
+
+// deviceSnapshots returns the snapshots of device, oldest first.
+func deviceSnapshots(snapshots []HealthSnapshot, device string) []HealthSnapshot {
+	var out []HealthSnapshot
+	for _, s := range snapshots {
+		if snapshotDevice(s) == device {
+			out = append(out, s)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Timestamp.Before(out[j].Timestamp) })
+	return out
+}
+
+// ComputeForecast projects device's endurance from its history. ratedBytes
+// is the rated write endurance; when zero it is estimated from the disk size
+// with DefaultTBWPerTB. At least two snapshots with a parseable data-written
+// value are needed.
+func ComputeForecast(snapshots []HealthSnapshot, device string, ratedBytes int64, now time.Time) (*Forecast, error) {
+	device = NormalizeDevice(device)
+
+	var written, worn []HealthSnapshot
+	for _, s := range deviceSnapshots(snapshots, device) {
+		s.parseNumeric()
+		if s.DataWrittenBytes > 0 {
+			written = append(written, s)
+		}
+		if s.WearPercent != nil {
+			worn = append(worn, s)
+		}
+	}
+
+	if len(written) < 2 {
+		return nil, fmt.Errorf("not enough history for %s: need at least 2 snapshots with data written, have %d", device, len(written))
+	}
+	first, last := written[0], written[len(written)-1]
+	days := last.Timestamp.Sub(first.Timestamp).Hours() / 24
+	if days <= 0 {
+		return nil, fmt.Errorf("not enough history for %s: snapshots span no time", device)
+	}
+
+	f := &Forecast{
+		Device:           device,
+		Since:            first.Timestamp,
+		Until:            last.Timestamp,
+		Samples:          len(written),
+		DataWrittenBytes: last.DataWrittenBytes,
+		WrittenBytes:     counterDelta(first.DataWrittenBytes, last.DataWrittenBytes),
+		RatedBytes:       ratedBytes,
+	}
+	f.BytesPerDay = float64(f.WrittenBytes) / days
+
+	if f.RatedBytes <= 0 && last.SizeBytes > 0 {
+		f.RatedBytes = last.SizeBytes * DefaultTBWPerTB
+		f.RatedEstimated = true
+	}
+	if f.RatedBytes > 0 && f.BytesPerDay > 0 {
+		remaining := max(float64(f.RatedBytes-f.DataWrittenBytes), 0)
+		d := remaining / f.BytesPerDay
+		f.DaysToRated = &d
+		f.RatedDate = projectDate(now, d)
+	}
+
+	if len(worn) > 0 {
+		cur := *worn[len(worn)-1].WearPercent
+		f.WearPercent = &cur
+	}
+	if len(worn) >= 2 {
+		a, b := worn[0], worn[len(worn)-1]
+		if span := b.Timestamp.Sub(a.Timestamp).Hours() / 24; span > 0 {
+			rate := (*b.WearPercent - *a.WearPercent) / span
+			f.WearPerDay = &rate
+			if rate > 0 {
+				d := max(100-*b.WearPercent, 0) / rate
+				f.DaysToFullWear = &d
+				f.FullWearDate = projectDate(now, d)
+			}
+		}
+	}
+
+	return f, nil
+}
+
+// projectDate returns now plus days, truncated to the day.
+func projectDate(now time.Time, days float64) time.Time {
+	// Cap far-off projections to avoid overflowing time.Duration.
+	const maxDays = 100 * 365
+	if days > maxDays {
+		days = maxDays
+	}
+	t := now.Add(time.Duration(days * float64(day)))
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// DailyWrites returns the bytes written per device per local day. Each
+// increase between consecutive snapshots of a device is counted on the day
+// of the later snapshot.
+func DailyWrites(snapshots []HealthSnapshot) []DailyWrite {
+	type key struct{ date, device string }
+	totals := make(map[key]int64)
+	var devices []string
+	seen := make(map[string]bool)
+	for _, s := range snapshots {
+		if d := snapshotDevice(s); !seen[d] {
+			seen[d] = true
+			devices = append(devices, d)
+		}
+	}
+
+	for _, dev := range devices {
+		var prev *HealthSnapshot
+		for _, s := range deviceSnapshots(snapshots, dev) {
+			s.parseNumeric()
+			if s.DataWrittenBytes == 0 {
+				continue
+			}
+			if prev != nil {
+				k := key{s.Timestamp.Local().Format("2006-01-02"), dev}
+				totals[k] += counterDelta(prev.DataWrittenBytes, s.DataWrittenBytes)
+			}
+			prev = &s
+		}
+	}
+
+	out := make([]DailyWrite, 0, len(totals))
+	for k, n := range totals {
+		out = append(out, DailyWrite{Date: k.date, Device: k.device, Bytes: n})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Date != out[j].Date {
+			return out[i].Date < out[j].Date
+		}
+		return out[i].Device < out[j].Device
+	})
+	return out
+}
@@ -0,0 +1,101 @@
+package disk
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestComputeForecast(t *testing.T) {
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	snapshots := []HealthSnapshot{
+		{Timestamp: start, WearLevel: "2%", DataWritten: "10 TB", SizeBytes: 1e12},
+		{Timestamp: start.Add(5 * day), Device: "disk4", DataWritten: "1 TB"},
+		{Timestamp: start.Add(10 * day), Device: "disk0", WearLevel: "3%", DataWritten: "11 TB", SizeBytes: 1e12},
+	}
+
+	now := start.Add(10 * day)
+	f, err := ComputeForecast(snapshots, "disk0", 0, now)
+	if err != nil {
+		t.Fatalf("ComputeForecast: %v", err)
+	}
+
+	if f.Samples != 2 || f.WrittenBytes != 1e12 || f.BytesPerDay != 1e11 {
+		t.Errorf("write rate = %+v", f)
+	}
+	if !f.RatedEstimated || f.RatedBytes != 600e12 {
+		t.Errorf("rated = %d (estimated %v), want 600 TB estimated", f.RatedBytes, f.RatedEstimated)
+	}
+	// 589 TB remaining at 0.1 TB/day.
+	if f.DaysToRated == nil || math.Abs(*f.DaysToRated-5890) > 1e-6 {
+		t.Errorf("DaysToRated = %v, want 5890", f.DaysToRated)
+	}
+	// 97% remaining at 0.1%/day.
+	if f.DaysToFullWear == nil || math.Abs(*f.DaysToFullWear-970) > 1e-6 {
+		t.Errorf("DaysToFullWear = %v, want 970", f.DaysToFullWear)
+	}
+	if got := f.FullWearDate.Format("2006-01-02"); got != "2028-09-07" {
+		t.Errorf("FullWearDate = %s", got)
+	}
+
+	rated, err := ComputeForecast(snapshots, "/dev/disk0", 12e12, now)
+	if err != nil {
+		t.Fatalf("ComputeForecast: %v", err)
+	}
+	if rated.RatedEstimated || rated.DaysToRated == nil || math.Abs(*rated.DaysToRated-10) > 1e-6 {
+		t.Errorf("rated forecast = %+v", rated)
+	}
+}
+
+func TestComputeForecastNotEnoughHistory(t *testing.T) {
+	snapshots := []HealthSnapshot{
+		{Timestamp: time.Now(), DataWritten: "1 TB"},
+		{Timestamp: time.Now(), DataWritten: "unavailable"},
+	}
+	if _, err := ComputeForecast(snapshots, "disk0", 0, time.Now()); err == nil {
+		t.Error("expected error with one usable snapshot")
+	}
+}
+
+func TestDailyWrites(t *testing.T) {
+	base := time.Date(2026, 3, 1, 9, 0, 0, 0, time.Local)
+	snapshots := []HealthSnapshot{
+		{Timestamp: base, DataWritten: "1.0 TB"},
+		{Timestamp: base.Add(2 * time.Hour), DataWritten: "1.1 TB"},
+		{Timestamp: base.Add(4 * time.Hour), DataWrittenBytes: 1.2e12},
+		{Timestamp: base.Add(day), DataWritten: "1.5 TB"},
+		{Timestamp: base, Device: "disk4", DataWritten: "100 GB"},
+		{Timestamp: base.Add(day), Device: "disk4", DataWritten: "unavailable"},
+		{Timestamp: base.Add(day + time.Hour), Device: "disk4", DataWritten: "150 GB"},
+	}
+
+	got := DailyWrites(snapshots)
+	want := []DailyWrite{
+		{Date: "2026-03-01", Device: "disk0", Bytes: 2e11},
+		{Date: "2026-03-02", Device: "disk0", Bytes: 3e11},
+		{Date: "2026-03-02", Device: "disk4", Bytes: 5e10},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("DailyWrites = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("DailyWrites[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestLoadHistoryParsesNumericFields(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	if err := SaveHistory([]HealthSnapshot{{Timestamp: time.Now(), WearLevel: "7%", DataWritten: "2 TB"}}); err != nil {
+		t.Fatalf("SaveHistory: %v", err)
+	}
+
+	got, err := LoadHistory()
+	if err != nil {
+		t.Fatalf("LoadHistory: %v", err)
+	}
+	if got[0].DataWrittenBytes != 2e12 || got[0].WearPercent == nil || *got[0].WearPercent != 7 {
+		t.Errorf("snapshot = %+v", got[0])
+	}
+}
@@ -11,8 +11,9 @@ import (
 )
 
 const (
-	// MaxHistoryEntries is the maximum number of disk history entries to keep.
-	MaxHistoryEntries = 500
+	// MaxHistoryAge is how long disk history entries are kept, for every
+	// device alike.
+	MaxHistoryAge = 30 * 24 * time.Hour
 
 	// DefaultHistoryCount is the default number of entries to show.
 	DefaultHistoryCount = 20
@@ -23,11 +24,33 @@ const (
 // HealthSnapshot holds a point-in-time disk health measurement.
 type HealthSnapshot struct {
 	Timestamp   time.Time `json:"timestamp"`
+	Device      string    `json:"device,omitempty"`
 	Model       string    `json:"model"`
 	SmartStatus string    `json:"smart_status"`
 	WearLevel   string    `json:"wear_level"`
 	DataWritten string    `json:"data_written"`
 	SizeBytes   int64     `json:"size_bytes"`
+
+	// Numeric forms of DataWritten and WearLevel, for trends and forecasts.
+	DataWrittenBytes int64    `json:"data_written_bytes,omitempty"`
+	WearPercent      *float64 `json:"wear_percent,omitempty"`
+
+	Smart *SmartAttributes `json:"smart,omitempty"`
+}
+
+// parseNumeric fills DataWrittenBytes and WearPercent from the raw strings
+// when they are parseable and not already set.
+func (s *HealthSnapshot) parseNumeric() {
+	if s.DataWrittenBytes == 0 {
+		if n, err := ParseBytes(s.DataWritten); err == nil {
+			s.DataWrittenBytes = n
+		}
+	}
+	if s.WearPercent == nil {
+		if v, err := ParsePercent(s.WearLevel); err == nil {
+			s.WearPercent = &v
+		}
+	}
 }
 
 // historyPath returns the path to the disk history file.
@@ -59,14 +82,30 @@ func LoadHistory() ([]HealthSnapshot, error) {
 		return nil, fmt.Errorf("failed to parse disk history file: %w", err)
 	}
 
+	// Entries recorded before the numeric fields existed.
+	for i := range snapshots {
+		snapshots[i].parseNumeric()
+	}
+
 	return snapshots, nil
 }
 
-// SaveHistory writes disk health snapshots to the history file.
-func SaveHistory(snapshots []HealthSnapshot) error {
-	if len(snapshots) > MaxHistoryEntries {
-		snapshots = snapshots[len(snapshots)-MaxHistoryEntries:]
+// TrimHistory returns the snapshots newer than MaxHistoryAge before now.
+func TrimHistory(snapshots []HealthSnapshot, now time.Time) []HealthSnapshot {
+	cutoff := now.Add(-MaxHistoryAge)
+	var kept []HealthSnapshot
+	for _, s := range snapshots {
+		if s.Timestamp.After(cutoff) {
+			kept = append(kept, s)
+		}
 	}
+	return kept
+}
+
+// SaveHistory writes disk health snapshots to the history file, dropping
+// entries older than MaxHistoryAge.
+func SaveHistory(snapshots []HealthSnapshot) error {
+	snapshots = TrimHistory(snapshots, time.Now())
 
 	path, err := historyPath()
 	if err != nil {
@@ -90,21 +129,37 @@ func SaveHistory(snapshots []HealthSnapshot) error {
 	return nil
 }
 
-// RecordSnapshot takes a disk health snapshot and appends it to history.
-func RecordSnapshot() (*HealthSnapshot, error) {
-	health, err := GetHealth()
+// RecordSnapshot takes a health snapshot of device and appends it to history.
+// SMART attributes are included when smartctl is installed.
+func RecordSnapshot(device string) (*HealthSnapshot, error) {
+	health, err := GetHealth(device)
 	if err != nil {
 		return nil, err
 	}
 
 	snap := &HealthSnapshot{
 		Timestamp:   time.Now().UTC(),
+		Device:      health.Device,
 		Model:       health.Model,
 		SmartStatus: health.SmartStatus,
 		WearLevel:   health.WearLevel,
 		DataWritten: health.DataWritten,
 		SizeBytes:   health.SizeBytes,
 	}
+	snap.parseNumeric()
+	if smart, err := GetSmart(health.Device); err == nil {
+		snap.Smart = smart
+		// Disks system_profiler doesn't cover still get numeric trends.
+		if snap.DataWrittenBytes == 0 {
+			snap.DataWrittenBytes = smart.DataWrittenBytes
+		}
+		// Percentage used comes from the NVMe log, which also reports
+		// data written; without it the zero value means nothing.
+		if snap.WearPercent == nil && smart.DataWrittenBytes > 0 {
+			used := float64(smart.PercentageUsed)
+			snap.WearPercent = &used
+		}
+	}
 
 	existing, err := LoadHistory()
 	if err != nil {
@@ -0,0 +1,146 @@
+package disk
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/lu-zhengda/macctl/internal/plist"
+)
+
+// Info holds the metadata `diskutil info -plist` reports for a disk,
+// partition or volume.
+type Info struct {
+	Device             string   `json:"device"`
+	DeviceNode         string   `json:"device_node,omitempty"`
+	MediaName          string   `json:"media_name,omitempty"`
+	Content            string   `json:"content,omitempty"`
+	VolumeName         string   `json:"volume_name,omitempty"`
+	MountPoint         string   `json:"mount_point,omitempty"`
+	FilesystemType     string   `json:"filesystem_type,omitempty"`
+	SizeBytes          int64    `json:"size_bytes"`
+	BlockSize          int64    `json:"block_size,omitempty"`
+	BusProtocol        string   `json:"bus_protocol,omitempty"`
+	VirtualOrPhysical  string   `json:"virtual_or_physical,omitempty"`
+	Internal           bool     `json:"internal"`
+	Removable          bool     `json:"removable"`
+	Ejectable          bool     `json:"ejectable"`
+	SolidState         bool     `json:"solid_state"`
+	TRIMSupported      bool     `json:"trim_supported"`
+	SmartStatus        string   `json:"smart_status,omitempty"`
+	WholeDisk          bool     `json:"whole_disk"`
+	ParentWholeDisk    string   `json:"parent_whole_disk,omitempty"`
+	APFSContainer      string   `json:"apfs_container,omitempty"`
+	APFSPhysicalStores []string `json:"apfs_physical_stores,omitempty"`
+	Encrypted          bool     `json:"encrypted"`
+	FileVault          bool     `json:"filevault"`
+}
+
+// diskutilInfo mirrors the fields of `diskutil info -plist` that we use.
+type diskutilInfo struct {
+	DeviceIdentifier       string `json:"DeviceIdentifier"`
+	DeviceNode             string `json:"DeviceNode"`
+	MediaName              string `json:"MediaName"`
+	IORegistryEntryName    string `json:"IORegistryEntryName"`
+	Content                string `json:"Content"`
+	VolumeName             string `json:"VolumeName"`
+	MountPoint             string `json:"MountPoint"`
+	FilesystemType         string `json:"FilesystemType"`
+	Size                   int64  `json:"Size"`
+	TotalSize              int64  `json:"TotalSize"`
+	DeviceBlockSize        int64  `json:"DeviceBlockSize"`
+	BusProtocol            string `json:"BusProtocol"`
+	VirtualOrPhysical      string `json:"VirtualOrPhysical"`
+	Internal               bool   `json:"Internal"`
+	Removable              bool   `json:"Removable"`
+	RemovableMedia         bool   `json:"RemovableMedia"`
+	Ejectable              bool   `json:"Ejectable"`
+	SolidState             bool   `json:"SolidState"`
+	TRIMSupport            bool   `json:"TRIMSupport"`
+	SMARTStatus            string `json:"SMARTStatus"`
+	WholeDisk              bool   `json:"WholeDisk"`
+	ParentWholeDisk        string `json:"ParentWholeDisk"`
+	APFSContainerReference string `json:"APFSContainerReference"`
+	APFSPhysicalStores     []struct {
+		APFSPhysicalStore string `json:"APFSPhysicalStore"`
+	} `json:"APFSPhysicalStores"`
+	Encryption bool `json:"Encryption"`
+	FileVault  bool `json:"FileVault"`
+}
+
+func parseInfoPlist(data []byte) (*Info, error) {
+	var raw diskutilInfo
+	if err := plist.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+
+	info := &Info{
+		Device:            raw.DeviceIdentifier,
+		DeviceNode:        raw.DeviceNode,
+		MediaName:         strings.TrimSpace(raw.MediaName),
+		Content:           raw.Content,
+		VolumeName:        raw.VolumeName,
+		MountPoint:        raw.MountPoint,
+		FilesystemType:    raw.FilesystemType,
+		SizeBytes:         raw.TotalSize,
+		BlockSize:         raw.DeviceBlockSize,
+		BusProtocol:       raw.BusProtocol,
+		VirtualOrPhysical: raw.VirtualOrPhysical,
+		Internal:          raw.Internal,
+		Removable:         raw.Removable || raw.RemovableMedia,
+		Ejectable:         raw.Ejectable,
+		SolidState:        raw.SolidState,
+		TRIMSupported:     raw.TRIMSupport,
+		SmartStatus:       raw.SMARTStatus,
+		WholeDisk:         raw.WholeDisk,
+		ParentWholeDisk:   raw.ParentWholeDisk,
+		APFSContainer:     raw.APFSContainerReference,
+		Encrypted:         raw.Encryption,
+		FileVault:         raw.FileVault,
+	}
+	if info.MediaName == "" {
+		info.MediaName = strings.TrimSpace(raw.IORegistryEntryName)
+	}
+	if info.SizeBytes == 0 {
+		info.SizeBytes = raw.Size
+	}
+	for _, s := range raw.APFSPhysicalStores {
+		info.APFSPhysicalStores = append(info.APFSPhysicalStores, s.APFSPhysicalStore)
+	}
+
+	return info, nil
+}
+
+// healthFromInfo builds the Health fields diskutil reports.
+func healthFromInfo(info *Info) *Health {
+	h := &Health{
+		Device:      info.Device,
+		Model:       info.MediaName,
+		Protocol:    info.BusProtocol,
+		SizeBytes:   info.SizeBytes,
+		SmartStatus: info.SmartStatus,
+		WearLevel:   "unavailable",
+		DataWritten: "unavailable",
+		Info:        info,
+	}
+	if h.SizeBytes > 0 {
+		// Same form as diskutil's text output, which SizeHuman has always held.
+		h.SizeHuman = fmt.Sprintf("%s (%d Bytes)", FormatBytes(h.SizeBytes), h.SizeBytes)
+	}
+	if h.SmartStatus == "" {
+		h.SmartStatus = "unknown"
+	}
+	return h
+}
+
+// GetInfo returns diskutil's metadata for a disk, partition or volume.
+func GetInfo(device string) (*Info, error) {
+	out, err := runDiskutilInfo(NormalizeDevice(device))
+	if err != nil {
+		return nil, err
+	}
+	info, err := parseInfoPlist(out)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse diskutil info: %w", err)
+	}
+	return info, nil
+}
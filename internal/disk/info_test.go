@@ -0,0 +1,166 @@
+package disk
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+const diskutilInfoPlist = `<?xml version="1.0" encoding="UTF-8"?>
+<plist version="1.0">
+<dict>
+	<key>BusProtocol</key>
+	<string>Apple Fabric</string>
+	<key>Content</key>
+	<string>GUID_partition_scheme</string>
+	<key>DeviceBlockSize</key>
+	<integer>4096</integer>
+	<key>DeviceIdentifier</key>
+	<string>disk0</string>
+	<key>DeviceNode</key>
+	<string>/dev/disk0</string>
+	<key>Ejectable</key>
+	<false/>
+	<key>Internal</key>
+	<true/>
+	<key>IORegistryEntryName</key>
+	<string>APPLE SSD AP0512Q Media</string>
+	<key>MediaName</key>
+	<string>APPLE SSD AP0512Q</string>
+	<key>Removable</key>
+	<false/>
+	<key>RemovableMedia</key>
+	<false/>
+	<key>SMARTStatus</key>
+	<string>Verified</string>
+	<key>Size</key>
+	<integer>500277790720</integer>
+	<key>SolidState</key>
+	<true/>
+	<key>TRIMSupport</key>
+	<true/>
+	<key>TotalSize</key>
+	<integer>500277790720</integer>
+	<key>VirtualOrPhysical</key>
+	<string>Physical</string>
+	<key>WholeDisk</key>
+	<true/>
+</dict>
+</plist>
+`
+
+func TestParseInfoPlist(t *testing.T) {
+	info, err := parseInfoPlist([]byte(diskutilInfoPlist))
+	if err != nil {
+		t.Fatalf("parseInfoPlist: %v", err)
+	}
+
+	want := &Info{
+		Device:            "disk0",
+		DeviceNode:        "/dev/disk0",
+		MediaName:         "APPLE SSD AP0512Q",
+		Content:           "GUID_partition_scheme",
+		SizeBytes:         500277790720,
+		BlockSize:         4096,
+		BusProtocol:       "Apple Fabric",
+		VirtualOrPhysical: "Physical",
+		Internal:          true,
+		SolidState:        true,
+		TRIMSupported:     true,
+		SmartStatus:       "Verified",
+		WholeDisk:         true,
+	}
+	if !reflect.DeepEqual(info, want) {
+		t.Errorf("parseInfoPlist =\n%+v\nwant\n%+v", info, want)
+	}
+}
+
+func TestParseInfoPlistAPFSVolume(t *testing.T) {
+	input := `<plist><dict>
+		<key>DeviceIdentifier</key><string>disk3s5</string>
+		<key>APFSContainerReference</key><string>disk3</string>
+		<key>APFSPhysicalStores</key>
+		<array><dict><key>APFSPhysicalStore</key><string>disk0s2</string></dict></array>
+		<key>Encryption</key><true/>
+		<key>FileVault</key><true/>
+		<key>RemovableMedia</key><true/>
+		<key>Size</key><integer>1000</integer>
+	</dict></plist>`
+
+	info, err := parseInfoPlist([]byte(input))
+	if err != nil {
+		t.Fatalf("parseInfoPlist: %v", err)
+	}
+	if info.APFSContainer != "disk3" {
+		t.Errorf("APFSContainer = %q, want disk3", info.APFSContainer)
+	}
+	if !reflect.DeepEqual(info.APFSPhysicalStores, []string{"disk0s2"}) {
+		t.Errorf("APFSPhysicalStores = %v", info.APFSPhysicalStores)
+	}
+	if !info.Encrypted || !info.FileVault || !info.Removable {
+		t.Errorf("flags = %+v", info)
+	}
+	if info.SizeBytes != 1000 {
+		t.Errorf("SizeBytes = %d, want fallback to Size", info.SizeBytes)
+	}
+}
+
+func TestHealthFromInfoKeepsJSONFields(t *testing.T) {
+	info, err := parseInfoPlist([]byte(diskutilInfoPlist))
+	if err != nil {
+		t.Fatalf("parseInfoPlist: %v", err)
+	}
+	h := healthFromInfo(info)
+
+	if h.Device != "disk0" || h.Model != "APPLE SSD AP0512Q" || h.Protocol != "Apple Fabric" {
+		t.Errorf("health = %+v", h)
+	}
+	if h.SizeHuman != "500.3 GB (500277790720 Bytes)" || h.SmartStatus != "Verified" || h.WearLevel != "unavailable" {
+		t.Errorf("health = %+v", h)
+	}
+
+	// The string round-trips through the text parser's size extraction.
+	if got := parseSizeBytes(h.SizeHuman); got != h.SizeBytes {
+		t.Errorf("parseSizeBytes(SizeHuman) = %d, want %d", got, h.SizeBytes)
+	}
+
+	data, err := json.Marshal(h)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	var fields map[string]any
+	if err := json.Unmarshal(data, &fields); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	for _, key := range []string{"device", "model", "protocol", "size_bytes", "size_human", "wear_level", "data_written", "smart_status", "info"} {
+		if _, ok := fields[key]; !ok {
+			t.Errorf("JSON missing key %q", key)
+		}
+	}
+}
+
+func TestGetHealthFromPlist(t *testing.T) {
+	orig := runDiskutilInfo
+	defer func() { runDiskutilInfo = orig }()
+
+	runDiskutilInfo = func(string) ([]byte, error) { return []byte(diskutilInfoPlist), nil }
+	h, err := GetHealth("disk0")
+	if err != nil {
+		t.Fatalf("GetHealth: %v", err)
+	}
+	if h.Info == nil || h.Device != "disk0" || !h.Info.TRIMSupported {
+		t.Errorf("GetHealth = %+v, want plist-derived info", h)
+	}
+
+	runDiskutilInfo = func(string) ([]byte, error) { return []byte("<plist><dict><key>"), nil }
+	if _, err := GetHealth("disk0"); err == nil {
+		t.Error("GetHealth with a malformed plist: expected error")
+	}
+
+	runErr := errors.New("diskutil failed")
+	runDiskutilInfo = func(string) ([]byte, error) { return nil, runErr }
+	if _, err := GetHealth("disk0"); !errors.Is(err, runErr) {
+		t.Errorf("GetHealth when diskutil fails: err = %v, want %v", err, runErr)
+	}
+}
@@ -0,0 +1,211 @@
+package disk
+
+import (
+	"fmt"
+	"os/exec"
+	"sort"
+	"time"
+
+	"github.com/lu-zhengda/macctl/internal/plist"
+)
+
+// DefaultIOInterval is the default time between the two counter samples.
+const DefaultIOInterval = time.Second
+
+// bytesPerMB matches the MB/s iostat and Activity Monitor report.
+const bytesPerMB = 1 << 20
+
+// IOStats holds current I/O rate information for one device. Latencies are
+// the average time per operation over the sample.
+type IOStats struct {
+	Timestamp      time.Time `json:"timestamp,omitzero"`
+	Device         string    `json:"device"`
+	ReadMBs        float64   `json:"read_mbs"`
+	WriteMBs       float64   `json:"write_mbs"`
+	ReadIOPS       float64   `json:"read_iops"`
+	WriteIOPS      float64   `json:"write_iops"`
+	ReadLatencyMS  float64   `json:"read_latency_ms"`
+	WriteLatencyMS float64   `json:"write_latency_ms"`
+}
+
+// IOCounters holds the cumulative I/O counters of a disk's
+// IOBlockStorageDriver, as reported by ioreg.
+type IOCounters struct {
+	Device       string `json:"device"`
+	BytesRead    int64  `json:"bytes_read"`
+	BytesWritten int64  `json:"bytes_written"`
+	ReadOps      int64  `json:"read_ops"`
+	WriteOps     int64  `json:"write_ops"`
+	ReadTimeNS   int64  `json:"read_time_ns"`
+	WriteTimeNS  int64  `json:"write_time_ns"`
+}
+
+// ReadIOCounters returns the current I/O counters of every whole disk,
+// keyed by device.
+func ReadIOCounters() (map[string]IOCounters, error) {
+	out, err := exec.Command("ioreg", "-a", "-r", "-c", "IOBlockStorageDriver", "-d", "2").Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to run ioreg: %w", err)
+	}
+	return parseIORegCounters(out)
+}
+
+// GetIOStats samples I/O counters twice, interval apart, and returns the
+// read and write rates of the given devices, or of every disk if none are
+// given.
+func GetIOStats(interval time.Duration, devices ...string) ([]IOStats, error) {
+	if interval <= 0 {
+		interval = DefaultIOInterval
+	}
+
+	before, err := ReadIOCounters()
+	if err != nil {
+		return nil, err
+	}
+	start := time.Now()
+	time.Sleep(interval)
+	after, err := ReadIOCounters()
+	if err != nil {
+		return nil, err
+	}
+
+	stats, err := diffIOCounters(before, after, time.Since(start), devices)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	for i := range stats {
+		stats[i].Timestamp = now
+	}
+	return stats, nil
+}
+
+// diffIOCounters returns the rates of the given devices, or of every disk
+// in after. Only named devices must appear in both samples; unnamed disks
+// attached or ejected between the samples are skipped.
+func diffIOCounters(before, after map[string]IOCounters, elapsed time.Duration, devices []string) ([]IOStats, error) {
+	explicit := len(devices) > 0
+	if !explicit {
+		for dev := range after {
+			devices = append(devices, dev)
+		}
+		sort.Strings(devices)
+	}
+
+	var stats []IOStats
+	for _, dev := range devices {
+		dev = NormalizeDevice(dev)
+		b, okB := before[dev]
+		a, okA := after[dev]
+		if !okB || !okA {
+			if !explicit {
+				continue
+			}
+			return nil, fmt.Errorf("no I/O statistics for %s", dev)
+		}
+		stats = append(stats, ComputeIOStats(b, a, elapsed))
+	}
+	return stats, nil
+}
+
+// ComputeIOStats returns the rates between two counter samples taken
+// elapsed apart. Counters that went backwards (e.g., after the disk was
+// reattached) count as zero.
+func ComputeIOStats(before, after IOCounters, elapsed time.Duration) IOStats {
+	s := IOStats{Device: after.Device}
+	secs := elapsed.Seconds()
+	if secs <= 0 {
+		return s
+	}
+
+	readOps := counterDelta(before.ReadOps, after.ReadOps)
+	writeOps := counterDelta(before.WriteOps, after.WriteOps)
+
+	s.ReadMBs = float64(counterDelta(before.BytesRead, after.BytesRead)) / bytesPerMB / secs
+	s.WriteMBs = float64(counterDelta(before.BytesWritten, after.BytesWritten)) / bytesPerMB / secs
+	s.ReadIOPS = float64(readOps) / secs
+	s.WriteIOPS = float64(writeOps) / secs
+	if readOps > 0 {
+		s.ReadLatencyMS = float64(counterDelta(before.ReadTimeNS, after.ReadTimeNS)) / float64(readOps) / 1e6
+	}
+	if writeOps > 0 {
+		s.WriteLatencyMS = float64(counterDelta(before.WriteTimeNS, after.WriteTimeNS)) / float64(writeOps) / 1e6
+	}
+	return s
+}
+
+func counterDelta(before, after int64) int64 {
+	if after < before {
+		return 0
+	}
+	return after - before
+}
+
+// parseIORegCounters parses `ioreg -a -r -c IOBlockStorageDriver` output:
+// an array of drivers, each with a Statistics dictionary and an IOMedia
+// child carrying the disk's BSD name.
+func parseIORegCounters(data []byte) (map[string]IOCounters, error) {
+	decoded, err := plist.Decode(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse ioreg output: %w", err)
+	}
+
+	drivers, ok := decoded.([]any)
+	if !ok {
+		return nil, fmt.Errorf("unexpected ioreg output")
+	}
+
+	counters := make(map[string]IOCounters)
+	for _, d := range drivers {
+		driver, ok := d.(map[string]any)
+		if !ok {
+			continue
+		}
+		stats, ok := driver["Statistics"].(map[string]any)
+		if !ok {
+			continue
+		}
+		device := childBSDName(driver)
+		if device == "" {
+			continue
+		}
+
+		counters[device] = IOCounters{
+			Device:       device,
+			BytesRead:    plistInt(stats["Bytes (Read)"]),
+			BytesWritten: plistInt(stats["Bytes (Write)"]),
+			ReadOps:      plistInt(stats["Operations (Read)"]),
+			WriteOps:     plistInt(stats["Operations (Write)"]),
+			ReadTimeNS:   plistInt(stats["Total Time (Read)"]),
+			WriteTimeNS:  plistInt(stats["Total Time (Write)"]),
+		}
+	}
+
+	return counters, nil
+}
+
+func childBSDName(entry map[string]any) string {
+	children, _ := entry["IORegistryEntryChildren"].([]any)
+	for _, c := range children {
+		child, ok := c.(map[string]any)
+		if !ok {
+			continue
+		}
+		if name, ok := child["BSD Name"].(string); ok && name != "" {
+			return name
+		}
+	}
+	return ""
+}
+
+func plistInt(v any) int64 {
+	switch n := v.(type) {
+	case int64:
+		return n
+	case uint64:
+		return int64(n)
+	case float64:
+		return int64(n)
+	}
+	return 0
+}
@@ -0,0 +1,179 @@
+package disk
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+const ioregPlist = `<?xml version="1.0" encoding="UTF-8"?>
+<plist version="1.0">
+<array>
+	<dict>
+		<key>IOObjectClass</key>
+		<string>IOBlockStorageDriver</string>
+		<key>Statistics</key>
+		<dict>
+			<key>Bytes (Read)</key>
+			<integer>1048576000</integer>
+			<key>Bytes (Write)</key>
+			<integer>524288000</integer>
+			<key>Operations (Read)</key>
+			<integer>1000</integer>
+			<key>Operations (Write)</key>
+			<integer>500</integer>
+			<key>Total Time (Read)</key>
+			<integer>2000000</integer>
+			<key>Total Time (Write)</key>
+			<integer>1000000</integer>
+		</dict>
+		<key>IORegistryEntryChildren</key>
+		<array>
+			<dict>
+				<key>BSD Name</key>
+				<string>disk0</string>
+				<key>IOObjectClass</key>
+				<string>IOMedia</string>
+			</dict>
+		</array>
+	</dict>
+	<dict>
+		<key>Statistics</key>
+		<dict>
+			<key>Bytes (Read)</key>
+			<integer>4096</integer>
+			<key>Bytes (Write)</key>
+			<integer>0</integer>
+			<key>Operations (Read)</key>
+			<integer>1</integer>
+			<key>Operations (Write)</key>
+			<integer>0</integer>
+		</dict>
+		<key>IORegistryEntryChildren</key>
+		<array>
+			<dict>
+				<key>BSD Name</key>
+				<string>disk4</string>
+			</dict>
+		</array>
+	</dict>
+	<dict>
+		<key>IOObjectClass</key>
+		<string>IOBlockStorageDriver</string>
+	</dict>
+</array>
+</plist>
+`
+
+func TestParseIORegCounters(t *testing.T) {
+	counters, err := parseIORegCounters([]byte(ioregPlist))
+	if err != nil {
+		t.Fatalf("parseIORegCounters: %v", err)
+	}
+
+	if len(counters) != 2 {
+		t.Fatalf("expected 2 devices, got %d: %+v", len(counters), counters)
+	}
+
+	want := IOCounters{
+		Device:       "disk0",
+		BytesRead:    1048576000,
+		BytesWritten: 524288000,
+		ReadOps:      1000,
+		WriteOps:     500,
+		ReadTimeNS:   2000000,
+		WriteTimeNS:  1000000,
+	}
+	if got := counters["disk0"]; got != want {
+		t.Errorf("disk0 = %+v, want %+v", got, want)
+	}
+	if got := counters["disk4"]; got.BytesRead != 4096 || got.ReadOps != 1 {
+		t.Errorf("disk4 = %+v", got)
+	}
+}
+
+func TestParseIORegCountersInvalid(t *testing.T) {
+	if _, err := parseIORegCounters([]byte("not a plist")); err == nil {
+		t.Error("expected error for invalid output")
+	}
+	if _, err := parseIORegCounters([]byte(`<plist><dict></dict></plist>`)); err == nil {
+		t.Error("expected error for non-array output")
+	}
+}
+
+func TestComputeIOStats(t *testing.T) {
+	before := IOCounters{Device: "disk0", BytesRead: 0, BytesWritten: 1 << 20, ReadOps: 10, WriteOps: 5}
+	after := IOCounters{Device: "disk0", BytesRead: 4 << 20, BytesWritten: 3 << 20, ReadOps: 210, WriteOps: 105}
+
+	got := ComputeIOStats(before, after, 2*time.Second)
+	want := IOStats{Device: "disk0", ReadMBs: 2, WriteMBs: 1, ReadIOPS: 100, WriteIOPS: 50}
+	if got != want {
+		t.Errorf("ComputeIOStats = %+v, want %+v", got, want)
+	}
+
+	// Counters that reset count as zero rather than negative.
+	reset := ComputeIOStats(after, before, time.Second)
+	if reset.ReadMBs != 0 || reset.WriteIOPS != 0 {
+		t.Errorf("reset counters = %+v, want zero rates", reset)
+	}
+
+	timed := ComputeIOStats(
+		IOCounters{ReadOps: 100, ReadTimeNS: 1e9, WriteOps: 0, WriteTimeNS: 0},
+		IOCounters{ReadOps: 300, ReadTimeNS: 1e9 + 400e6, WriteOps: 10, WriteTimeNS: 50e6},
+		time.Second)
+	if timed.ReadLatencyMS != 2 || timed.WriteLatencyMS != 5 {
+		t.Errorf("latency = %v/%v ms, want 2/5", timed.ReadLatencyMS, timed.WriteLatencyMS)
+	}
+
+	if zero := ComputeIOStats(before, after, 0); zero.ReadMBs != 0 || math.IsInf(zero.ReadMBs, 0) {
+		t.Errorf("zero elapsed = %+v, want zero rates", zero)
+	}
+}
+
+func TestDiffIOCounters(t *testing.T) {
+	before := map[string]IOCounters{
+		"disk0": {Device: "disk0"},
+		"disk4": {Device: "disk4"},
+	}
+	after := map[string]IOCounters{
+		"disk0": {Device: "disk0", ReadOps: 10},
+		"disk4": {Device: "disk4", WriteOps: 20},
+	}
+
+	all, err := diffIOCounters(before, after, time.Second, nil)
+	if err != nil {
+		t.Fatalf("diffIOCounters: %v", err)
+	}
+	if len(all) != 2 || all[0].Device != "disk0" || all[1].Device != "disk4" {
+		t.Errorf("all devices = %+v", all)
+	}
+
+	one, err := diffIOCounters(before, after, time.Second, []string{"/dev/disk4"})
+	if err != nil {
+		t.Fatalf("diffIOCounters: %v", err)
+	}
+	if len(one) != 1 || one[0].WriteIOPS != 20 {
+		t.Errorf("disk4 = %+v", one)
+	}
+
+	if _, err := diffIOCounters(before, after, time.Second, []string{"disk9"}); err == nil {
+		t.Error("expected error for unknown device")
+	}
+
+	// Disks attached or ejected between samples are skipped when no device
+	// is named, as with disk io --all --watch.
+	plugged := map[string]IOCounters{
+		"disk0": {Device: "disk0", ReadOps: 10},
+		"disk5": {Device: "disk5", ReadOps: 3},
+	}
+	changed, err := diffIOCounters(before, plugged, time.Second, nil)
+	if err != nil {
+		t.Fatalf("diffIOCounters with hot-plugged disk: %v", err)
+	}
+	if len(changed) != 1 || changed[0].Device != "disk0" {
+		t.Errorf("hot-plugged devices = %+v, want only disk0", changed)
+	}
+	if _, err := diffIOCounters(before, plugged, time.Second, []string{"disk4"}); err == nil {
+		t.Error("expected error for an ejected device named explicitly")
+	}
+}
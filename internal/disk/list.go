@@ -0,0 +1,199 @@
+package disk
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/lu-zhengda/macctl/internal/plist"
+)
+
+// DefaultDevice is the disk used when no device is given: the internal
+// boot disk on every Mac.
+const DefaultDevice = "disk0"
+
+// Disk type constants.
+const (
+	TypeInternal  = "internal"
+	TypeExternal  = "external"
+	TypeDiskImage = "disk_image"
+)
+
+// Disk is a physical disk or attached disk image.
+type Disk struct {
+	Device    string   `json:"device"`
+	Model     string   `json:"model"`
+	SizeBytes int64    `json:"size_bytes"`
+	SizeHuman string   `json:"size_human"`
+	Type      string   `json:"type"`
+	Protocol  string   `json:"protocol"`
+	Removable bool     `json:"removable"`
+	Volumes   []Volume `json:"volumes,omitempty"`
+}
+
+// Volume is a partition or APFS volume on a disk.
+type Volume struct {
+	Device     string `json:"device"`
+	Name       string `json:"name,omitempty"`
+	MountPoint string `json:"mount_point,omitempty"`
+	Content    string `json:"content,omitempty"`
+	SizeBytes  int64  `json:"size_bytes"`
+}
+
+// diskutilList mirrors the fields of `diskutil list -plist` that we use.
+type diskutilList struct {
+	AllDisksAndPartitions []diskutilListEntry `json:"AllDisksAndPartitions"`
+}
+
+type diskutilListEntry struct {
+	DeviceIdentifier   string              `json:"DeviceIdentifier"`
+	Content            string              `json:"Content"`
+	Size               int64               `json:"Size"`
+	Partitions         []diskutilPartition `json:"Partitions"`
+	APFSVolumes        []diskutilPartition `json:"APFSVolumes"`
+	APFSPhysicalStores []struct {
+		DeviceIdentifier string `json:"DeviceIdentifier"`
+	} `json:"APFSPhysicalStores"`
+}
+
+type diskutilPartition struct {
+	DeviceIdentifier string `json:"DeviceIdentifier"`
+	Content          string `json:"Content"`
+	VolumeName       string `json:"VolumeName"`
+	MountPoint       string `json:"MountPoint"`
+	Size             int64  `json:"Size"`
+}
+
+// List returns every physical disk and attached disk image, with the volumes
+// on each. Synthesized APFS container disks are folded into the disk that
+// holds their physical store.
+func List() ([]Disk, error) {
+	out, err := exec.Command("diskutil", "list", "-plist").Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to run diskutil list: %w", err)
+	}
+
+	disks, err := parseDiskList(out)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range disks {
+		info, err := runDiskutilInfo(disks[i].Device)
+		if err != nil {
+			return nil, err
+		}
+		if err := applyDiskInfo(&disks[i], info); err != nil {
+			return nil, err
+		}
+	}
+
+	return disks, nil
+}
+
+// NormalizeDevice turns "/dev/disk4" or "disk4" into "disk4".
+func NormalizeDevice(device string) string {
+	device = strings.TrimSpace(device)
+	device = strings.TrimPrefix(device, "/dev/")
+	if strings.HasPrefix(device, "rdisk") {
+		device = device[1:]
+	}
+	return device
+}
+
+func parseDiskList(data []byte) ([]Disk, error) {
+	var list diskutilList
+	if err := plist.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("failed to parse diskutil list: %w", err)
+	}
+
+	// APFS containers keyed by the partition that stores them.
+	containers := make(map[string]diskutilListEntry)
+	for _, e := range list.AllDisksAndPartitions {
+		for _, store := range e.APFSPhysicalStores {
+			containers[store.DeviceIdentifier] = e
+		}
+	}
+
+	var disks []Disk
+	for _, e := range list.AllDisksAndPartitions {
+		if len(e.APFSPhysicalStores) > 0 {
+			continue
+		}
+
+		d := Disk{
+			Device:    e.DeviceIdentifier,
+			SizeBytes: e.Size,
+			SizeHuman: FormatBytes(e.Size),
+		}
+		for _, p := range e.Partitions {
+			d.Volumes = append(d.Volumes, p.volume())
+			if c, ok := containers[p.DeviceIdentifier]; ok {
+				for _, v := range c.APFSVolumes {
+					d.Volumes = append(d.Volumes, v.volume())
+				}
+			}
+		}
+		// Unpartitioned media, such as some disk images, may hold an APFS
+		// container directly.
+		if c, ok := containers[e.DeviceIdentifier]; ok {
+			for _, v := range c.APFSVolumes {
+				d.Volumes = append(d.Volumes, v.volume())
+			}
+		}
+
+		disks = append(disks, d)
+	}
+
+	return disks, nil
+}
+
+func (p diskutilPartition) volume() Volume {
+	return Volume{
+		Device:     p.DeviceIdentifier,
+		Name:       p.VolumeName,
+		MountPoint: p.MountPoint,
+		Content:    p.Content,
+		SizeBytes:  p.Size,
+	}
+}
+
+func applyDiskInfo(d *Disk, data []byte) error {
+	info, err := parseInfoPlist(data)
+	if err != nil {
+		return fmt.Errorf("failed to parse diskutil info %s: %w", d.Device, err)
+	}
+
+	d.Model = info.MediaName
+	d.Protocol = info.BusProtocol
+	d.Removable = info.Removable
+	if info.SizeBytes > 0 {
+		d.SizeBytes = info.SizeBytes
+		d.SizeHuman = FormatBytes(info.SizeBytes)
+	}
+
+	switch {
+	case info.BusProtocol == "Disk Image" || info.VirtualOrPhysical == "Virtual":
+		d.Type = TypeDiskImage
+	case info.Internal:
+		d.Type = TypeInternal
+	default:
+		d.Type = TypeExternal
+	}
+
+	return nil
+}
+
+// FormatBytes formats a byte count in decimal units, as diskutil does.
+func FormatBytes(n int64) string {
+	const unit = 1000
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit && exp < 5; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,194 @@
+package disk
+
+import "testing"
+
+const diskutilListPlist = `<?xml version="1.0" encoding="UTF-8"?>
+<plist version="1.0">
+<dict>
+	<key>AllDisksAndPartitions</key>
+	<array>
+		<dict>
+			<key>Content</key>
+			<string>GUID_partition_scheme</string>
+			<key>DeviceIdentifier</key>
+			<string>disk0</string>
+			<key>Size</key>
+			<integer>500277790720</integer>
+			<key>Partitions</key>
+			<array>
+				<dict>
+					<key>Content</key>
+					<string>Apple_APFS_ISC</string>
+					<key>DeviceIdentifier</key>
+					<string>disk0s1</string>
+					<key>Size</key>
+					<integer>524288000</integer>
+				</dict>
+				<dict>
+					<key>Content</key>
+					<string>Apple_APFS</string>
+					<key>DeviceIdentifier</key>
+					<string>disk0s2</string>
+					<key>Size</key>
+					<integer>494384795648</integer>
+				</dict>
+			</array>
+		</dict>
+		<dict>
+			<key>APFSPhysicalStores</key>
+			<array>
+				<dict>
+					<key>DeviceIdentifier</key>
+					<string>disk0s2</string>
+				</dict>
+			</array>
+			<key>APFSVolumes</key>
+			<array>
+				<dict>
+					<key>DeviceIdentifier</key>
+					<string>disk3s1</string>
+					<key>MountPoint</key>
+					<string>/System/Volumes/Data</string>
+					<key>Size</key>
+					<integer>494384795648</integer>
+					<key>VolumeName</key>
+					<string>Data</string>
+				</dict>
+			</array>
+			<key>Content</key>
+			<string>EF57347C-0000-11AA-AA11-00306543ECAC</string>
+			<key>DeviceIdentifier</key>
+			<string>disk3</string>
+			<key>Size</key>
+			<integer>494384795648</integer>
+		</dict>
+		<dict>
+			<key>Content</key>
+			<string>FDisk_partition_scheme</string>
+			<key>DeviceIdentifier</key>
+			<string>disk4</string>
+			<key>Size</key>
+			<integer>64023257088</integer>
+			<key>Partitions</key>
+			<array>
+				<dict>
+					<key>Content</key>
+					<string>Windows_NTFS</string>
+					<key>DeviceIdentifier</key>
+					<string>disk4s1</string>
+					<key>MountPoint</key>
+					<string>/Volumes/USB</string>
+					<key>Size</key>
+					<integer>64022208512</integer>
+					<key>VolumeName</key>
+					<string>USB</string>
+				</dict>
+			</array>
+		</dict>
+	</array>
+</dict>
+</plist>
+`
+
+func TestParseDiskList(t *testing.T) {
+	disks, err := parseDiskList([]byte(diskutilListPlist))
+	if err != nil {
+		t.Fatalf("parseDiskList: %v", err)
+	}
+
+	if len(disks) != 2 {
+		t.Fatalf("expected 2 disks (synthesized container skipped), got %d: %+v", len(disks), disks)
+	}
+
+	internal := disks[0]
+	if internal.Device != "disk0" || internal.SizeBytes != 500277790720 {
+		t.Errorf("disk0 = %+v", internal)
+	}
+	if len(internal.Volumes) != 3 {
+		t.Fatalf("expected 2 partitions + 1 APFS volume on disk0, got %+v", internal.Volumes)
+	}
+	data := internal.Volumes[2]
+	if data.Device != "disk3s1" || data.Name != "Data" || data.MountPoint != "/System/Volumes/Data" {
+		t.Errorf("APFS volume = %+v", data)
+	}
+
+	usb := disks[1]
+	if usb.Device != "disk4" || len(usb.Volumes) != 1 || usb.Volumes[0].Name != "USB" {
+		t.Errorf("disk4 = %+v", usb)
+	}
+}
+
+func TestApplyDiskInfo(t *testing.T) {
+	tests := []struct {
+		name     string
+		info     string
+		wantType string
+		wantRem  bool
+	}{
+		{
+			name:     "internal",
+			info:     `<dict><key>MediaName</key><string>APPLE SSD AP0512Q</string><key>Internal</key><true/><key>BusProtocol</key><string>Apple Fabric</string><key>VirtualOrPhysical</key><string>Physical</string><key>TotalSize</key><integer>500277790720</integer></dict>`,
+			wantType: TypeInternal,
+		},
+		{
+			name:     "external usb",
+			info:     `<dict><key>MediaName</key><string>SanDisk Extreme</string><key>Internal</key><false/><key>Removable</key><true/><key>BusProtocol</key><string>USB</string><key>VirtualOrPhysical</key><string>Physical</string></dict>`,
+			wantType: TypeExternal,
+			wantRem:  true,
+		},
+		{
+			name:     "disk image",
+			info:     `<dict><key>MediaName</key><string>Disk Image</string><key>Internal</key><false/><key>BusProtocol</key><string>Disk Image</string><key>VirtualOrPhysical</key><string>Virtual</string></dict>`,
+			wantType: TypeDiskImage,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := Disk{Device: "disk9", SizeBytes: 1}
+			if err := applyDiskInfo(&d, []byte(`<plist>`+tt.info+`</plist>`)); err != nil {
+				t.Fatalf("applyDiskInfo: %v", err)
+			}
+			if d.Type != tt.wantType {
+				t.Errorf("Type = %q, want %q", d.Type, tt.wantType)
+			}
+			if d.Removable != tt.wantRem {
+				t.Errorf("Removable = %v, want %v", d.Removable, tt.wantRem)
+			}
+			if d.Model == "" || d.Protocol == "" {
+				t.Errorf("Model/Protocol not set: %+v", d)
+			}
+		})
+	}
+}
+
+func TestNormalizeDevice(t *testing.T) {
+	tests := map[string]string{
+		"disk4":       "disk4",
+		"/dev/disk4":  "disk4",
+		"/dev/rdisk4": "disk4",
+		" disk0s1 ":   "disk0s1",
+	}
+	for input, want := range tests {
+		if got := NormalizeDevice(input); got != want {
+			t.Errorf("NormalizeDevice(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
+
+func TestFormatBytes(t *testing.T) {
+	tests := []struct {
+		n    int64
+		want string
+	}{
+		{512, "512 B"},
+		{1500, "1.5 KB"},
+		{500107862016, "500.1 GB"},
+		{2000000000000, "2.0 TB"},
+	}
+	for _, tt := range tests {
+		if got := FormatBytes(tt.n); got != tt.want {
+			t.Errorf("FormatBytes(%d) = %q, want %q", tt.n, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,314 @@
+package disk
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// AllExternal is the eject target that selects every external disk.
+const AllExternal = "all-external"
+
+// Process is a process holding files open on a volume.
+type Process struct {
+	PID     int    `json:"pid"`
+	Command string `json:"command"`
+	User    string `json:"user,omitempty"`
+}
+
+// BusyError reports a volume that could not be unmounted and the processes
+// keeping it busy.
+type BusyError struct {
+	Device     string    `json:"device"`
+	MountPoint string    `json:"mount_point"`
+	Processes  []Process `json:"blocking_processes"`
+	Err        error     `json:"-"`
+}
+
+func (e *BusyError) Error() string {
+	name := e.Device
+	if e.MountPoint != "" {
+		name = fmt.Sprintf("%s (%s)", e.MountPoint, e.Device)
+	}
+	if len(e.Processes) == 0 {
+		return fmt.Sprintf("failed to unmount %s: %v", name, e.Err)
+	}
+	var procs []string
+	for _, p := range e.Processes {
+		procs = append(procs, fmt.Sprintf("%s (%d)", p.Command, p.PID))
+	}
+	return fmt.Sprintf("%s is in use by %s; quit them or use --force", name, strings.Join(procs, ", "))
+}
+
+func (e *BusyError) Unwrap() error { return e.Err }
+
+// EjectResult lists the disks an eject removed and those it could not.
+type EjectResult struct {
+	Ejected []Disk         `json:"ejected"`
+	Failed  []EjectFailure `json:"failed"`
+}
+
+// EjectFailure is a disk that could not be ejected. BlockingProcesses is set
+// when one of its volumes was busy.
+type EjectFailure struct {
+	Device            string    `json:"device"`
+	Error             string    `json:"error"`
+	BlockingProcesses []Process `json:"blocking_processes,omitempty"`
+}
+
+// MountVolume mounts the volume with the given name, mount point or device.
+func MountVolume(name string) (*Volume, error) {
+	disks, err := List()
+	if err != nil {
+		return nil, err
+	}
+	_, v, err := ResolveVolume(disks, name)
+	if err != nil {
+		return nil, err
+	}
+	if err := runDiskutil("mount", v.Device); err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+// UnmountVolume unmounts the volume with the given name, mount point or
+// device. If it is busy, the error is a *BusyError listing the processes
+// using it.
+func UnmountVolume(name string, force bool) (*Volume, error) {
+	disks, err := List()
+	if err != nil {
+		return nil, err
+	}
+	_, v, err := ResolveVolume(disks, name)
+	if err != nil {
+		return nil, err
+	}
+
+	args := []string{"unmount"}
+	if force {
+		args = append(args, "force")
+	}
+	if err := runDiskutil(append(args, v.Device)...); err != nil {
+		return nil, busyError(v, err)
+	}
+	return &v, nil
+}
+
+// Eject unmounts and ejects the disk named by target: a disk device, or the
+// name, mount point or device of one of its volumes. AllExternal ejects
+// every external disk; a failure on one doesn't stop the others. It returns
+// the disks ejected and those that failed, along with the failures joined
+// into one error.
+func Eject(target string, force bool) (*EjectResult, error) {
+	disks, err := List()
+	if err != nil {
+		return nil, err
+	}
+
+	var targets []Disk
+	if target == AllExternal {
+		targets = ExternalDisks(disks)
+	} else {
+		d, err := ResolveDisk(disks, target)
+		if err != nil {
+			return nil, err
+		}
+		if d.Type == TypeInternal {
+			return nil, fmt.Errorf("%s is an internal disk and cannot be ejected", d.Device)
+		}
+		targets = []Disk{d}
+	}
+
+	return ejectAll(targets, force, ejectDisk)
+}
+
+// ejectAll ejects every target, even after failures, so one busy drive
+// doesn't keep the others attached. It returns the result and the failures
+// joined.
+func ejectAll(targets []Disk, force bool, eject func(Disk, bool) error) (*EjectResult, error) {
+	result := &EjectResult{Ejected: []Disk{}, Failed: []EjectFailure{}}
+	var errs []error
+	for _, d := range targets {
+		err := eject(d, force)
+		if err == nil {
+			result.Ejected = append(result.Ejected, d)
+			continue
+		}
+		errs = append(errs, err)
+
+		f := EjectFailure{Device: d.Device, Error: err.Error()}
+		var busy *BusyError
+		if errors.As(err, &busy) {
+			f.BlockingProcesses = busy.Processes
+		}
+		result.Failed = append(result.Failed, f)
+	}
+	return result, errors.Join(errs...)
+}
+
+// ejectDisk unmounts and ejects d. If a volume is busy, the error is a
+// *BusyError listing the processes using it.
+func ejectDisk(d Disk, force bool) error {
+	if force {
+		if err := runDiskutil("unmountDisk", "force", d.Device); err != nil {
+			return fmt.Errorf("%s: %w", d.Device, err)
+		}
+	}
+	if err := runDiskutil("eject", d.Device); err != nil {
+		for _, v := range d.Volumes {
+			if v.MountPoint == "" {
+				continue
+			}
+			if busy := busyError(v, err); len(busy.Processes) > 0 {
+				return busy
+			}
+		}
+		return fmt.Errorf("%s: %w", d.Device, err)
+	}
+	return nil
+}
+
+// ExternalDisks returns the external physical disks in disks.
+func ExternalDisks(disks []Disk) []Disk {
+	var out []Disk
+	for _, d := range disks {
+		if d.Type == TypeExternal {
+			out = append(out, d)
+		}
+	}
+	return out
+}
+
+// ResolveVolume finds the volume matching name: its device, mount point, or
+// volume name (case-insensitive). It returns the volume and its disk.
+func ResolveVolume(disks []Disk, name string) (Disk, Volume, error) {
+	dev := NormalizeDevice(name)
+	clean := strings.TrimRight(name, "/")
+
+	type match struct {
+		disk   Disk
+		volume Volume
+	}
+	var matches []match
+	for _, d := range disks {
+		for _, v := range d.Volumes {
+			switch {
+			case v.Device == dev,
+				v.MountPoint != "" && (v.MountPoint == clean || v.MountPoint == name),
+				v.Name != "" && strings.EqualFold(v.Name, name),
+				v.MountPoint != "" && filepath.Base(v.MountPoint) == name && strings.HasPrefix(v.MountPoint, "/Volumes/"):
+				matches = append(matches, match{d, v})
+			}
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return Disk{}, Volume{}, fmt.Errorf("no volume named %q", name)
+	case 1:
+		return matches[0].disk, matches[0].volume, nil
+	}
+
+	var devices []string
+	for _, m := range matches {
+		devices = append(devices, m.volume.Device)
+	}
+	return Disk{}, Volume{}, fmt.Errorf("%q matches several volumes (%s); use a device identifier", name, strings.Join(devices, ", "))
+}
+
+// ResolveDisk finds the disk matching name: a whole-disk device, or any
+// name ResolveVolume accepts for one of its volumes.
+func ResolveDisk(disks []Disk, name string) (Disk, error) {
+	dev := NormalizeDevice(name)
+	for _, d := range disks {
+		if d.Device == dev {
+			return d, nil
+		}
+	}
+	d, _, err := ResolveVolume(disks, name)
+	if err != nil {
+		return Disk{}, fmt.Errorf("no disk or volume named %q", name)
+	}
+	return d, nil
+}
+
+// BlockingProcesses returns the processes with files open on the volume
+// mounted at mountPoint.
+func BlockingProcesses(mountPoint string) ([]Process, error) {
+	out, err := exec.Command("lsof", "-F", "pcL", "+f", "--", mountPoint).Output()
+	return lsofResult(out, err)
+}
+
+// lsofResult interprets lsof's output and error. lsof exits 1 both when no
+// files are open and on partial failures, such as permission denied on some
+// files, so whatever it listed is used regardless of the exit status.
+func lsofResult(out []byte, err error) ([]Process, error) {
+	if len(out) > 0 {
+		return parseLsof(string(out)), nil
+	}
+	var exitErr *exec.ExitError
+	if err == nil || (errors.As(err, &exitErr) && exitErr.ExitCode() == 1) {
+		return nil, nil
+	}
+	return nil, fmt.Errorf("failed to run lsof: %w", err)
+}
+
+// parseLsof parses `lsof -F pcL` output, where each process starts with a
+// "p<pid>" line followed by "c<command>" and "L<login>" lines.
+func parseLsof(output string) []Process {
+	seen := make(map[int]bool)
+	var procs []Process
+	var cur *Process
+	for _, line := range strings.Split(output, "\n") {
+		if len(line) < 2 {
+			continue
+		}
+		switch line[0] {
+		case 'p':
+			pid, err := strconv.Atoi(line[1:])
+			if err != nil || seen[pid] {
+				cur = nil
+				continue
+			}
+			seen[pid] = true
+			procs = append(procs, Process{PID: pid})
+			cur = &procs[len(procs)-1]
+		case 'c':
+			if cur != nil {
+				cur.Command = line[1:]
+			}
+		case 'L':
+			if cur != nil {
+				cur.User = line[1:]
+			}
+		}
+	}
+	sort.Slice(procs, func(i, j int) bool { return procs[i].PID < procs[j].PID })
+	return procs
+}
+
+// busyError wraps an unmount failure with the processes using v.
+func busyError(v Volume, err error) *BusyError {
+	e := &BusyError{Device: v.Device, MountPoint: v.MountPoint, Err: err}
+	if v.MountPoint != "" {
+		e.Processes, _ = BlockingProcesses(v.MountPoint)
+	}
+	return e
+}
+
+func runDiskutil(args ...string) error {
+	out, err := exec.Command("diskutil", args...).CombinedOutput()
+	if err != nil {
+		msg := strings.TrimSpace(string(out))
+		if msg == "" {
+			return fmt.Errorf("failed to run diskutil %s: %w", args[0], err)
+		}
+		return fmt.Errorf("failed to run diskutil %s: %s", args[0], msg)
+	}
+	return nil
+}
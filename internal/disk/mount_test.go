@@ -0,0 +1,237 @@
+package disk
+
+import (
+	"encoding/json"
+	"errors"
+	"os/exec"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var mountTestDisks = []Disk{
+	{
+		Device: "disk0",
+		Type:   TypeInternal,
+		Volumes: []Volume{
+			{Device: "disk3s1", Name: "Macintosh HD", MountPoint: "/"},
+			{Device: "disk3s5", Name: "Data", MountPoint: "/System/Volumes/Data"},
+		},
+	},
+	{
+		Device: "disk4",
+		Type:   TypeExternal,
+		Volumes: []Volume{
+			{Device: "disk4s1", Name: "USB Stick", MountPoint: "/Volumes/USB Stick"},
+		},
+	},
+	{
+		Device: "disk5",
+		Type:   TypeExternal,
+		Volumes: []Volume{
+			{Device: "disk6s1", Name: "Backups"},
+			{Device: "disk6s2", Name: "Data", MountPoint: "/Volumes/Data"},
+		},
+	},
+	{Device: "disk7", Type: TypeDiskImage},
+}
+
+func TestResolveVolume(t *testing.T) {
+	tests := []struct {
+		name       string
+		wantDisk   string
+		wantVolume string
+	}{
+		{"disk4s1", "disk4", "disk4s1"},
+		{"/dev/disk4s1", "disk4", "disk4s1"},
+		{"usb stick", "disk4", "disk4s1"},
+		{"/Volumes/USB Stick/", "disk4", "disk4s1"},
+		{"Backups", "disk5", "disk6s1"},
+		{"/Volumes/Data", "disk5", "disk6s2"},
+	}
+	for _, tt := range tests {
+		d, v, err := ResolveVolume(mountTestDisks, tt.name)
+		if err != nil {
+			t.Errorf("ResolveVolume(%q): %v", tt.name, err)
+			continue
+		}
+		if d.Device != tt.wantDisk || v.Device != tt.wantVolume {
+			t.Errorf("ResolveVolume(%q) = %s/%s, want %s/%s", tt.name, d.Device, v.Device, tt.wantDisk, tt.wantVolume)
+		}
+	}
+
+	if _, _, err := ResolveVolume(mountTestDisks, "Data"); err == nil || !strings.Contains(err.Error(), "disk3s5, disk6s2") {
+		t.Errorf("ambiguous name error = %v", err)
+	}
+	if _, _, err := ResolveVolume(mountTestDisks, "Nope"); err == nil {
+		t.Error("expected error for unknown volume")
+	}
+}
+
+func TestResolveDisk(t *testing.T) {
+	for name, want := range map[string]string{
+		"disk4":      "disk4",
+		"/dev/disk7": "disk7",
+		"USB Stick":  "disk4",
+		"disk6s1":    "disk5",
+	} {
+		d, err := ResolveDisk(mountTestDisks, name)
+		if err != nil || d.Device != want {
+			t.Errorf("ResolveDisk(%q) = %s, %v; want %s", name, d.Device, err, want)
+		}
+	}
+	if _, err := ResolveDisk(mountTestDisks, "disk9"); err == nil {
+		t.Error("expected error for unknown disk")
+	}
+}
+
+func TestExternalDisks(t *testing.T) {
+	var got []string
+	for _, d := range ExternalDisks(mountTestDisks) {
+		got = append(got, d.Device)
+	}
+	if want := []string{"disk4", "disk5"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ExternalDisks = %v, want %v", got, want)
+	}
+}
+
+func TestParseLsof(t *testing.T) {
+	output := `p812
+cFinder
+Lalice
+p4051
+cmdworker_shared
+Lroot
+p812
+cFinder
+Lalice
+`
+	want := []Process{
+		{PID: 812, Command: "Finder", User: "alice"},
+		{PID: 4051, Command: "mdworker_shared", User: "root"},
+	}
+	if got := parseLsof(output); !reflect.DeepEqual(got, want) {
+		t.Errorf("parseLsof = %+v, want %+v", got, want)
+	}
+	if got := parseLsof(""); got != nil {
+		t.Errorf("parseLsof(empty) = %+v, want nil", got)
+	}
+}
+
+func TestBusyError(t *testing.T) {
+	cause := errors.New("diskutil failed")
+	busy := &BusyError{
+		Device:     "disk4s1",
+		MountPoint: "/Volumes/USB Stick",
+		Processes:  []Process{{PID: 812, Command: "Finder"}, {PID: 4051, Command: "Preview"}},
+		Err:        cause,
+	}
+
+	want := "/Volumes/USB Stick (disk4s1) is in use by Finder (812), Preview (4051); quit them or use --force"
+	if got := busy.Error(); got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+	if !errors.Is(busy, cause) {
+		t.Error("BusyError should unwrap to its cause")
+	}
+
+	unmounted := &BusyError{Device: "disk6s1", Err: cause}
+	if got := unmounted.Error(); got != "failed to unmount disk6s1: diskutil failed" {
+		t.Errorf("Error() = %q", got)
+	}
+}
+
+func TestEjectAllContinuesAfterFailures(t *testing.T) {
+	targets := ExternalDisks(append(mountTestDisks, Disk{Device: "disk8", Type: TypeExternal}))
+	busy := &BusyError{Device: "disk4s1", MountPoint: "/Volumes/USB Stick", Processes: []Process{{PID: 1, Command: "Finder"}}}
+
+	var tried []string
+	result, err := ejectAll(targets, false, func(d Disk, _ bool) error {
+		tried = append(tried, d.Device)
+		if d.Device == "disk4" {
+			return busy
+		}
+		return nil
+	})
+
+	if want := []string{"disk4", "disk5", "disk8"}; !reflect.DeepEqual(tried, want) {
+		t.Errorf("tried = %v, want %v", tried, want)
+	}
+	ejected := result.Ejected
+	if len(ejected) != 2 || ejected[0].Device != "disk5" || ejected[1].Device != "disk8" {
+		t.Errorf("ejected = %+v, want disk5 and disk8", ejected)
+	}
+	var got *BusyError
+	if !errors.As(err, &got) || got != busy {
+		t.Errorf("err = %v, want the busy error for disk4", err)
+	}
+
+	if result, err := ejectAll(targets[1:], false, func(Disk, bool) error { return nil }); err != nil || len(result.Ejected) != 2 {
+		t.Errorf("all succeed = %+v, %v", result, err)
+	}
+}
+
+func TestEjectResultJSON(t *testing.T) {
+	targets := []Disk{{Device: "disk4"}, {Device: "disk5"}}
+	busy := &BusyError{Device: "disk4s1", MountPoint: "/Volumes/USB Stick", Processes: []Process{{PID: 812, Command: "Finder", User: "alice"}}}
+
+	result, _ := ejectAll(targets, false, func(d Disk, _ bool) error {
+		if d.Device == "disk4" {
+			return busy
+		}
+		return nil
+	})
+	data, err := json.Marshal(result)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got struct {
+		Ejected []struct {
+			Device string `json:"device"`
+		} `json:"ejected"`
+		Failed []struct {
+			Device            string    `json:"device"`
+			Error             string    `json:"error"`
+			BlockingProcesses []Process `json:"blocking_processes"`
+		} `json:"failed"`
+	}
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("unmarshal %s: %v", data, err)
+	}
+	if len(got.Ejected) != 1 || got.Ejected[0].Device != "disk5" {
+		t.Errorf("ejected = %+v, want disk5", got.Ejected)
+	}
+	if len(got.Failed) != 1 || got.Failed[0].Device != "disk4" || got.Failed[0].Error != busy.Error() ||
+		len(got.Failed[0].BlockingProcesses) != 1 || got.Failed[0].BlockingProcesses[0].PID != 812 {
+		t.Errorf("failed = %+v, want disk4 blocked by Finder", got.Failed)
+	}
+
+	// Nothing failed: both keys are still present as arrays.
+	result, _ = ejectAll(nil, false, nil)
+	data, _ = json.Marshal(result)
+	if want := `{"ejected":[],"failed":[]}`; string(data) != want {
+		t.Errorf("empty result = %s, want %s", data, want)
+	}
+}
+
+func TestLsofResult(t *testing.T) {
+	// A real exit status of 1, as lsof reports partial failures.
+	exitErr := exec.Command("sh", "-c", "exit 1").Run()
+	if exitErr == nil {
+		t.Skip("sh unavailable")
+	}
+
+	procs, err := lsofResult([]byte("p812\ncFinder\nLalice\n"), exitErr)
+	if err != nil || len(procs) != 1 || procs[0].Command != "Finder" {
+		t.Errorf("partial failure = %+v, %v; want Finder", procs, err)
+	}
+
+	if procs, err := lsofResult(nil, exitErr); err != nil || procs != nil {
+		t.Errorf("nothing open = %+v, %v; want none", procs, err)
+	}
+
+	if _, err := lsofResult(nil, errors.New("exec: \"lsof\": executable file not found")); err == nil {
+		t.Error("expected error when lsof cannot run")
+	}
+}
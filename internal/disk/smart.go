@@ -0,0 +1,138 @@
+package disk
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+)
+
+// ErrSmartctlNotFound is returned when smartmontools is not installed.
+var ErrSmartctlNotFound = errors.New("smartctl not found (install with: brew install smartmontools)")
+
+// nvmeDataUnit is the size of an NVMe "data unit": 1000 512-byte blocks.
+const nvmeDataUnit = 512 * 1000
+
+// smartctl exit status bits that mean no data was read: bit 0 is a
+// command-line error, bit 1 a failure to open the device.
+const smartctlFatalBits = 0x03
+
+// SmartAttributes holds the SMART attributes smartctl reports beyond what
+// system_profiler exposes.
+type SmartAttributes struct {
+	Passed                  *bool `json:"passed,omitempty"`
+	CriticalWarning         int64 `json:"critical_warning"`
+	TemperatureC            int64 `json:"temperature_c"`
+	AvailableSpare          int64 `json:"available_spare"`
+	AvailableSpareThreshold int64 `json:"available_spare_threshold"`
+	PercentageUsed          int64 `json:"percentage_used"`
+	DataReadBytes           int64 `json:"data_read_bytes"`
+	DataWrittenBytes        int64 `json:"data_written_bytes"`
+	PowerOnHours            int64 `json:"power_on_hours"`
+	PowerCycles             int64 `json:"power_cycles"`
+	UnsafeShutdowns         int64 `json:"unsafe_shutdowns"`
+	MediaErrors             int64 `json:"media_errors"`
+	ErrorLogEntries         int64 `json:"error_log_entries"`
+}
+
+// smartctlOutput mirrors the fields of `smartctl -a -j` that we use.
+type smartctlOutput struct {
+	Smartctl struct {
+		ExitStatus int `json:"exit_status"`
+		Messages   []struct {
+			String   string `json:"string"`
+			Severity string `json:"severity"`
+		} `json:"messages"`
+	} `json:"smartctl"`
+	SmartStatus *struct {
+		Passed bool `json:"passed"`
+	} `json:"smart_status"`
+	Temperature struct {
+		Current int64 `json:"current"`
+	} `json:"temperature"`
+	PowerOnTime struct {
+		Hours int64 `json:"hours"`
+	} `json:"power_on_time"`
+	PowerCycleCount int64 `json:"power_cycle_count"`
+	NVMeLog         *struct {
+		CriticalWarning         int64 `json:"critical_warning"`
+		Temperature             int64 `json:"temperature"`
+		AvailableSpare          int64 `json:"available_spare"`
+		AvailableSpareThreshold int64 `json:"available_spare_threshold"`
+		PercentageUsed          int64 `json:"percentage_used"`
+		DataUnitsRead           int64 `json:"data_units_read"`
+		DataUnitsWritten        int64 `json:"data_units_written"`
+		PowerCycles             int64 `json:"power_cycles"`
+		PowerOnHours            int64 `json:"power_on_hours"`
+		UnsafeShutdowns         int64 `json:"unsafe_shutdowns"`
+		MediaErrors             int64 `json:"media_errors"`
+		NumErrLogEntries        int64 `json:"num_err_log_entries"`
+	} `json:"nvme_smart_health_information_log"`
+}
+
+// GetSmart returns the SMART attributes of device from smartctl. It returns
+// ErrSmartctlNotFound if smartctl is not installed.
+func GetSmart(device string) (*SmartAttributes, error) {
+	path, err := exec.LookPath("smartctl")
+	if err != nil {
+		return nil, ErrSmartctlNotFound
+	}
+
+	device = NormalizeDevice(device)
+	// smartctl signals warnings through its exit status, so a non-zero exit
+	// still carries a usable report.
+	out, err := exec.Command(path, "-a", "-j", "/dev/"+device).Output()
+	var exitErr *exec.ExitError
+	if err != nil && !errors.As(err, &exitErr) {
+		return nil, fmt.Errorf("failed to run smartctl: %w", err)
+	}
+
+	return parseSmartctl(out)
+}
+
+func parseSmartctl(data []byte) (*SmartAttributes, error) {
+	var out smartctlOutput
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil, fmt.Errorf("failed to parse smartctl output: %w", err)
+	}
+
+	if out.Smartctl.ExitStatus&smartctlFatalBits != 0 {
+		msg := "no SMART data"
+		for _, m := range out.Smartctl.Messages {
+			if m.Severity == "error" {
+				msg = m.String
+				break
+			}
+		}
+		return nil, fmt.Errorf("smartctl failed: %s", msg)
+	}
+
+	s := &SmartAttributes{
+		TemperatureC: out.Temperature.Current,
+		PowerOnHours: out.PowerOnTime.Hours,
+		PowerCycles:  out.PowerCycleCount,
+	}
+	if out.SmartStatus != nil {
+		passed := out.SmartStatus.Passed
+		s.Passed = &passed
+	}
+
+	if l := out.NVMeLog; l != nil {
+		s.CriticalWarning = l.CriticalWarning
+		s.AvailableSpare = l.AvailableSpare
+		s.AvailableSpareThreshold = l.AvailableSpareThreshold
+		s.PercentageUsed = l.PercentageUsed
+		s.DataReadBytes = l.DataUnitsRead * nvmeDataUnit
+		s.DataWrittenBytes = l.DataUnitsWritten * nvmeDataUnit
+		s.PowerCycles = l.PowerCycles
+		s.PowerOnHours = l.PowerOnHours
+		s.UnsafeShutdowns = l.UnsafeShutdowns
+		s.MediaErrors = l.MediaErrors
+		s.ErrorLogEntries = l.NumErrLogEntries
+		if l.Temperature > 0 {
+			s.TemperatureC = l.Temperature
+		}
+	}
+
+	return s, nil
+}
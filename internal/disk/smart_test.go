@@ -0,0 +1,82 @@
+package disk
+
+import "testing"
+
+const smartctlNVMeJSON = `{
+  "json_format_version": [1, 0],
+  "smartctl": {"version": [7, 4], "exit_status": 4},
+  "device": {"name": "/dev/disk0", "type": "nvme", "protocol": "NVMe"},
+  "model_name": "APPLE SSD AP0512Q",
+  "smart_status": {"passed": true, "nvme": {"value": 0}},
+  "nvme_smart_health_information_log": {
+    "critical_warning": 0,
+    "temperature": 36,
+    "available_spare": 100,
+    "available_spare_threshold": 99,
+    "percentage_used": 3,
+    "data_units_read": 40000000,
+    "data_units_written": 25000000,
+    "host_reads": 900000000,
+    "host_writes": 700000000,
+    "controller_busy_time": 0,
+    "power_cycles": 412,
+    "power_on_hours": 2315,
+    "unsafe_shutdowns": 17,
+    "media_errors": 0,
+    "num_err_log_entries": 2
+  },
+  "temperature": {"current": 35},
+  "power_cycle_count": 412,
+  "power_on_time": {"hours": 2315}
+}`
+
+func TestParseSmartctl(t *testing.T) {
+	s, err := parseSmartctl([]byte(smartctlNVMeJSON))
+	if err != nil {
+		t.Fatalf("parseSmartctl: %v", err)
+	}
+
+	if s.Passed == nil || !*s.Passed {
+		t.Errorf("Passed = %v, want true", s.Passed)
+	}
+	s.Passed = nil
+	want := SmartAttributes{
+		TemperatureC:            36,
+		AvailableSpare:          100,
+		AvailableSpareThreshold: 99,
+		PercentageUsed:          3,
+		DataReadBytes:           40000000 * 512000,
+		DataWrittenBytes:        25000000 * 512000,
+		PowerOnHours:            2315,
+		PowerCycles:             412,
+		UnsafeShutdowns:         17,
+		ErrorLogEntries:         2,
+	}
+	if *s != want {
+		t.Errorf("parseSmartctl =\n%+v\nwant\n%+v", *s, want)
+	}
+}
+
+func TestParseSmartctlNonNVMe(t *testing.T) {
+	input := `{"smartctl": {"exit_status": 0}, "smart_status": {"passed": false},
+		"temperature": {"current": 41}, "power_on_time": {"hours": 120}, "power_cycle_count": 9}`
+
+	s, err := parseSmartctl([]byte(input))
+	if err != nil {
+		t.Fatalf("parseSmartctl: %v", err)
+	}
+	if s.Passed == nil || *s.Passed || s.TemperatureC != 41 || s.PowerOnHours != 120 || s.PowerCycles != 9 {
+		t.Errorf("parseSmartctl = %+v", s)
+	}
+}
+
+func TestParseSmartctlErrors(t *testing.T) {
+	failed := `{"smartctl": {"exit_status": 2, "messages": [
+		{"string": "/dev/disk9: Unable to detect device type", "severity": "error"}]}}`
+	if _, err := parseSmartctl([]byte(failed)); err == nil {
+		t.Error("expected error when smartctl could not open the device")
+	}
+	if _, err := parseSmartctl([]byte("not json")); err == nil {
+		t.Error("expected error for invalid output")
+	}
+}
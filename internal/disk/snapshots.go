@@ -0,0 +1,164 @@
+package disk
+
+import (
+	"fmt"
+	"os/exec"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// DefaultSnapshotVolume is the volume whose local snapshots are managed by
+// default.
+const DefaultSnapshotVolume = "/"
+
+// thinUrgency is the urgency passed to `tmutil thinlocalsnapshots`, from 1
+// (least) to 4 (most); 4 thins until the requested space is free.
+const thinUrgency = 4
+
+const snapshotDateLayout = "2006-01-02-150405"
+
+// snapshotNameRe matches local snapshot names such as
+// "com.apple.TimeMachine.2026-01-15-103045.local" and captures the date.
+var snapshotNameRe = regexp.MustCompile(`(\d{4}-\d{2}-\d{2}-\d{6})`)
+
+// LocalSnapshot is an APFS local snapshot, such as those Time Machine takes.
+// It has no size: neither tmutil nor diskutil reports per-snapshot usage.
+type LocalSnapshot struct {
+	Name   string    `json:"name"`
+	Date   time.Time `json:"date,omitzero"`
+	Volume string    `json:"volume"`
+}
+
+// DateString returns the snapshot date in the form tmutil expects.
+func (s LocalSnapshot) DateString() string {
+	if m := snapshotNameRe.FindStringSubmatch(s.Name); m != nil {
+		return m[1]
+	}
+	return ""
+}
+
+// ListSnapshots returns the local snapshots of volume, oldest first.
+func ListSnapshots(volume string) ([]LocalSnapshot, error) {
+	out, err := exec.Command("tmutil", "listlocalsnapshots", volume).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to run tmutil listlocalsnapshots: %w", err)
+	}
+	return parseLocalSnapshots(string(out), volume), nil
+}
+
+// parseLocalSnapshots parses `tmutil listlocalsnapshots` output: a
+// "Snapshots for disk /:" header followed by one snapshot name per line.
+func parseLocalSnapshots(output, volume string) []LocalSnapshot {
+	var snaps []LocalSnapshot
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasSuffix(line, ":") {
+			continue
+		}
+
+		s := LocalSnapshot{Name: line, Volume: volume}
+		if d := s.DateString(); d != "" {
+			if t, err := time.ParseInLocation(snapshotDateLayout, d, time.Local); err == nil {
+				s.Date = t
+			}
+		}
+		snaps = append(snaps, s)
+	}
+
+	sort.SliceStable(snaps, func(i, j int) bool { return snaps[i].Date.Before(snaps[j].Date) })
+	return snaps
+}
+
+// SnapshotsBeyond returns the Time Machine snapshots to delete so that only
+// the newest keep remain. Snapshots without a Time Machine date, such as
+// those macOS takes for updates, are never selected. snaps must be sorted
+// oldest first.
+func SnapshotsBeyond(snaps []LocalSnapshot, keep int) []LocalSnapshot {
+	if keep < 0 {
+		keep = 0
+	}
+	var dated []LocalSnapshot
+	for _, s := range snaps {
+		if !s.Date.IsZero() {
+			dated = append(dated, s)
+		}
+	}
+	if len(dated) <= keep {
+		return nil
+	}
+	return dated[:len(dated)-keep]
+}
+
+// DeleteSnapshot deletes a local snapshot from its volume.
+func DeleteSnapshot(s LocalSnapshot) error {
+	args, err := deleteSnapshotArgs(s)
+	if err != nil {
+		return err
+	}
+	if out, err := exec.Command("tmutil", args...).CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to delete snapshot %s: %s: %w", s.Name, strings.TrimSpace(string(out)), err)
+	}
+	return nil
+}
+
+// deleteSnapshotArgs returns the tmutil arguments that delete s, scoped to
+// its volume so snapshots with the same date on other volumes are kept.
+func deleteSnapshotArgs(s LocalSnapshot) ([]string, error) {
+	date := s.DateString()
+	if date == "" {
+		return nil, fmt.Errorf("cannot delete %s: no date in snapshot name", s.Name)
+	}
+	volume := s.Volume
+	if volume == "" {
+		volume = DefaultSnapshotVolume
+	}
+	return []string{"deletelocalsnapshots", "-d", volume, date}, nil
+}
+
+// ThinSnapshots asks macOS to delete local snapshots of volume, oldest
+// first, until freeBytes have been reclaimed. It returns the dates of the
+// thinned snapshots.
+func ThinSnapshots(volume string, freeBytes int64) ([]string, error) {
+	out, err := exec.Command("tmutil", "thinlocalsnapshots", volume,
+		strconv.FormatInt(freeBytes, 10), strconv.Itoa(thinUrgency)).CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("failed to thin local snapshots: %s: %w", strings.TrimSpace(string(out)), err)
+	}
+	return parseThinned(string(out)), nil
+}
+
+// parseThinned parses the snapshot dates `tmutil thinlocalsnapshots` lists
+// after "Thinned local snapshots:".
+func parseThinned(output string) []string {
+	var dates []string
+	for _, line := range strings.Split(output, "\n") {
+		if m := snapshotNameRe.FindStringSubmatch(line); m != nil {
+			dates = append(dates, m[1])
+		}
+	}
+	return dates
+}
+
+// FreeBytes returns the space available to unprivileged users on the
+// filesystem holding path.
+func FreeBytes(path string) (int64, error) {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(path, &st); err != nil {
+		return 0, fmt.Errorf("failed to stat filesystem %s: %w", path, err)
+	}
+	return int64(st.Bavail) * int64(st.Bsize), nil
+}
+
+// ThinResult reports the outcome of thinning local snapshots.
+type ThinResult struct {
+	Volume     string          `json:"volume"`
+	DryRun     bool            `json:"dry_run"`
+	Keep       *int            `json:"keep,omitempty"`
+	FreeTarget int64           `json:"free_target_bytes,omitempty"`
+	Deleted    []LocalSnapshot `json:"deleted"`
+	FreedBytes int64           `json:"freed_bytes"`
+}
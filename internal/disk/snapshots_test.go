@@ -0,0 +1,110 @@
+package disk
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseLocalSnapshots(t *testing.T) {
+	output := `Snapshots for disk /:
+com.apple.TimeMachine.2026-10-17-093012.local
+com.apple.TimeMachine.2026-10-16-213455.local
+com.apple.os.update-ABCDEF
+com.apple.TimeMachine.2026-10-18-080000.local
+`
+	snaps := parseLocalSnapshots(output, "/")
+	if len(snaps) != 4 {
+		t.Fatalf("expected 4 snapshots, got %d: %+v", len(snaps), snaps)
+	}
+
+	// Undated snapshots sort first, then oldest to newest.
+	var names []string
+	for _, s := range snaps {
+		names = append(names, s.Name)
+	}
+	want := []string{
+		"com.apple.os.update-ABCDEF",
+		"com.apple.TimeMachine.2026-10-16-213455.local",
+		"com.apple.TimeMachine.2026-10-17-093012.local",
+		"com.apple.TimeMachine.2026-10-18-080000.local",
+	}
+	if !reflect.DeepEqual(names, want) {
+		t.Errorf("order = %v, want %v", names, want)
+	}
+
+	s := snaps[1]
+	if wantDate := time.Date(2026, 10, 16, 21, 34, 55, 0, time.Local); !s.Date.Equal(wantDate) {
+		t.Errorf("Date = %v, want %v", s.Date, wantDate)
+	}
+	if s.DateString() != "2026-10-16-213455" || s.Volume != "/" {
+		t.Errorf("snapshot = %+v", s)
+	}
+	if !snaps[0].Date.IsZero() || snaps[0].DateString() != "" {
+		t.Errorf("undated snapshot = %+v", snaps[0])
+	}
+}
+
+func TestSnapshotsBeyond(t *testing.T) {
+	snaps := parseLocalSnapshots(`Snapshots for disk /:
+com.apple.os.update-ABCDEF
+com.apple.TimeMachine.2026-10-16-213455.local
+com.apple.TimeMachine.2026-10-17-093012.local
+com.apple.TimeMachine.2026-10-18-080000.local
+`, "/")
+
+	tests := []struct {
+		keep int
+		want int
+	}{
+		{0, 3},
+		{1, 2},
+		{3, 0},
+		{5, 0},
+		{-1, 3},
+	}
+	for _, tt := range tests {
+		got := SnapshotsBeyond(snaps, tt.keep)
+		if len(got) != tt.want {
+			t.Errorf("SnapshotsBeyond(keep=%d) = %d snapshots, want %d", tt.keep, len(got), tt.want)
+		}
+		for _, s := range got {
+			if s.Date.IsZero() {
+				t.Errorf("SnapshotsBeyond(keep=%d) selected undated snapshot %s", tt.keep, s.Name)
+			}
+		}
+		if len(got) > 0 && got[0].DateString() != "2026-10-16-213455" {
+			t.Errorf("SnapshotsBeyond(keep=%d) should delete oldest first, got %+v", tt.keep, got)
+		}
+	}
+}
+
+func TestDeleteSnapshotArgs(t *testing.T) {
+	s := LocalSnapshot{Name: "com.apple.TimeMachine.2026-10-16-213455.local", Volume: "/Volumes/Work"}
+	args, err := deleteSnapshotArgs(s)
+	if err != nil {
+		t.Fatalf("deleteSnapshotArgs: %v", err)
+	}
+	want := []string{"deletelocalsnapshots", "-d", "/Volumes/Work", "2026-10-16-213455"}
+	if !reflect.DeepEqual(args, want) {
+		t.Errorf("args = %v, want %v", args, want)
+	}
+
+	if _, err := deleteSnapshotArgs(LocalSnapshot{Name: "com.apple.os.update-ABCDEF", Volume: "/"}); err == nil {
+		t.Error("expected error for undated snapshot")
+	}
+}
+
+func TestParseThinned(t *testing.T) {
+	output := `Thinned local snapshots:
+2026-10-16-213455
+2026-10-17-093012
+`
+	want := []string{"2026-10-16-213455", "2026-10-17-093012"}
+	if got := parseThinned(output); !reflect.DeepEqual(got, want) {
+		t.Errorf("parseThinned = %v, want %v", got, want)
+	}
+	if got := parseThinned(""); got != nil {
+		t.Errorf("parseThinned(empty) = %v, want nil", got)
+	}
+}
@@ -0,0 +1,67 @@
+package disk
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// bytesRe matches upper-cased sizes like "12.3 TB", "500 GIB", "20G" or
+// "1,234 BYTES".
+var bytesRe = regexp.MustCompile(`^([\d.,]+)\s*([KMGTP]I?B?|BYTES?|B)?$`)
+
+var byteUnits = map[string]float64{
+	"":      1,
+	"B":     1,
+	"BYTE":  1,
+	"BYTES": 1,
+	"KB":    1e3,
+	"MB":    1e6,
+	"GB":    1e9,
+	"TB":    1e12,
+	"PB":    1e15,
+	"KIB":   1 << 10,
+	"MIB":   1 << 20,
+	"GIB":   1 << 30,
+	"TIB":   1 << 40,
+	"PIB":   1 << 50,
+}
+
+// ParseBytes parses a size such as "12.3 TB", "500 GiB", "20G" or
+// diskutil's "500.1 GB (500107862016 Bytes)" into bytes. Units without "i"
+// are decimal, as macOS reports them.
+func ParseBytes(s string) (int64, error) {
+	s = strings.TrimSpace(s)
+	if n := parseSizeBytes(s); n > 0 {
+		return n, nil
+	}
+	if i := strings.Index(s, "("); i > 0 {
+		s = strings.TrimSpace(s[:i])
+	}
+
+	m := bytesRe.FindStringSubmatch(strings.ToUpper(s))
+	if m == nil {
+		return 0, fmt.Errorf("invalid size: %q", s)
+	}
+
+	v, err := strconv.ParseFloat(strings.ReplaceAll(m[1], ",", ""), 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid size: %q", s)
+	}
+	unit := m[2]
+	if unit != "" && !strings.HasSuffix(unit, "B") && !strings.HasPrefix(unit, "BYTE") {
+		unit += "B"
+	}
+	return int64(v * byteUnits[unit]), nil
+}
+
+// ParsePercent parses a percentage such as "3%" or "3 %".
+func ParsePercent(s string) (float64, error) {
+	t := strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(s), "%"))
+	v, err := strconv.ParseFloat(t, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid percentage: %q", s)
+	}
+	return v, nil
+}
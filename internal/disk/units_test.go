@@ -0,0 +1,48 @@
+package disk
+
+import "testing"
+
+func TestParseBytes(t *testing.T) {
+	tests := []struct {
+		input string
+		want  int64
+	}{
+		{"12.3 TB", 12300000000000},
+		{"500 GB", 500000000000},
+		{"1 GiB", 1 << 30},
+		{"1,024 bytes", 1024},
+		{"4096", 4096},
+		{"500.1 GB (500107862016 Bytes)", 500107862016},
+		{"1.5tb", 1500000000000},
+		{"20G", 20000000000},
+		{"2Gi", 2 << 30},
+	}
+	for _, tt := range tests {
+		got, err := ParseBytes(tt.input)
+		if err != nil {
+			t.Errorf("ParseBytes(%q): %v", tt.input, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseBytes(%q) = %d, want %d", tt.input, got, tt.want)
+		}
+	}
+
+	for _, bad := range []string{"", "unavailable", "12 XB"} {
+		if _, err := ParseBytes(bad); err == nil {
+			t.Errorf("ParseBytes(%q): expected error", bad)
+		}
+	}
+}
+
+func TestParsePercent(t *testing.T) {
+	for input, want := range map[string]float64{"3%": 3, " 12 % ": 12, "0.5%": 0.5} {
+		got, err := ParsePercent(input)
+		if err != nil || got != want {
+			t.Errorf("ParsePercent(%q) = %v, %v; want %v", input, got, err, want)
+		}
+	}
+	if _, err := ParsePercent("unavailable"); err == nil {
+		t.Error("expected error for unavailable")
+	}
+}
@@ -0,0 +1,188 @@
+package disk
+
+import (
+	"fmt"
+	"os/exec"
+
+	"github.com/lu-zhengda/macctl/internal/plist"
+)
+
+// Space status constants.
+const (
+	SpaceOK       = "ok"
+	SpaceLow      = "low"
+	SpaceCritical = "critical"
+
+	// SpaceUnknown is reported for containers without a known capacity.
+	SpaceUnknown = "unknown"
+)
+
+// Default free-space thresholds, as a percentage of container capacity.
+const (
+	DefaultLowFreePercent      = 15
+	DefaultCriticalFreePercent = 5
+)
+
+// Thresholds sets the free-space percentages below which a container is
+// flagged as low or critical.
+type Thresholds struct {
+	LowPercent      float64 `json:"low_percent"`
+	CriticalPercent float64 `json:"critical_percent"`
+}
+
+// Status returns the space status for the given free percentage.
+func (t Thresholds) Status(freePercent float64) string {
+	switch {
+	case freePercent < t.CriticalPercent:
+		return SpaceCritical
+	case freePercent < t.LowPercent:
+		return SpaceLow
+	}
+	return SpaceOK
+}
+
+// APFSContainer is an APFS container and the volumes sharing its space.
+type APFSContainer struct {
+	Device        string       `json:"device"`
+	UUID          string       `json:"uuid,omitempty"`
+	PhysicalStore string       `json:"physical_store,omitempty"`
+	CapacityBytes int64        `json:"capacity_bytes"`
+	UsedBytes     int64        `json:"used_bytes"`
+	FreeBytes     int64        `json:"free_bytes"`
+	FreePercent   float64      `json:"free_percent"`
+	Status        string       `json:"status"`
+	Volumes       []APFSVolume `json:"volumes"`
+}
+
+// APFSVolume is a volume in an APFS container.
+type APFSVolume struct {
+	Device         string   `json:"device"`
+	Name           string   `json:"name"`
+	Role           string   `json:"role,omitempty"`
+	Roles          []string `json:"roles,omitempty"`
+	MountPoint     string   `json:"mount_point,omitempty"`
+	UsedBytes      int64    `json:"used_bytes"`
+	QuotaBytes     int64    `json:"quota_bytes,omitempty"`
+	ReserveBytes   int64    `json:"reserve_bytes,omitempty"`
+	PurgeableBytes int64    `json:"purgeable_bytes,omitempty"`
+	Encrypted      bool     `json:"encrypted"`
+	FileVault      bool     `json:"filevault"`
+	Locked         bool     `json:"locked"`
+}
+
+// Usage is the space usage of every APFS container.
+type Usage struct {
+	Thresholds Thresholds      `json:"thresholds"`
+	Containers []APFSContainer `json:"containers"`
+}
+
+// diskutilAPFSList mirrors the fields of `diskutil apfs list -plist` that we
+// use.
+type diskutilAPFSList struct {
+	Containers []struct {
+		APFSContainerUUID       string `json:"APFSContainerUUID"`
+		ContainerReference      string `json:"ContainerReference"`
+		DesignatedPhysicalStore string `json:"DesignatedPhysicalStore"`
+		CapacityCeiling         int64  `json:"CapacityCeiling"`
+		CapacityFree            int64  `json:"CapacityFree"`
+		Volumes                 []struct {
+			DeviceIdentifier string   `json:"DeviceIdentifier"`
+			Name             string   `json:"Name"`
+			Roles            []string `json:"Roles"`
+			CapacityInUse    int64    `json:"CapacityInUse"`
+			CapacityQuota    int64    `json:"CapacityQuota"`
+			CapacityReserve  int64    `json:"CapacityReserve"`
+			Encryption       bool     `json:"Encryption"`
+			FileVault        bool     `json:"FileVault"`
+			Locked           bool     `json:"Locked"`
+		} `json:"Volumes"`
+	} `json:"Containers"`
+}
+
+// volumeSpace holds the per-volume fields of `diskutil info -plist` that
+// `diskutil apfs list` lacks. Purgeable space is only reported by some
+// macOS versions.
+type volumeSpace struct {
+	MountPoint     string `json:"MountPoint"`
+	PurgeableSpace int64  `json:"PurgeableSpace"`
+}
+
+// GetUsage returns the space usage of every APFS container, flagging those
+// with less free space than the thresholds allow.
+func GetUsage(t Thresholds) (*Usage, error) {
+	out, err := exec.Command("diskutil", "apfs", "list", "-plist").Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to run diskutil apfs list: %w", err)
+	}
+
+	containers, err := parseAPFSList(out, t)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range containers {
+		for j := range containers[i].Volumes {
+			v := &containers[i].Volumes[j]
+			info, err := runDiskutilInfo(v.Device)
+			if err != nil {
+				// Locked or unmounted volumes may not report details.
+				continue
+			}
+			var space volumeSpace
+			if err := plist.Unmarshal(info, &space); err == nil {
+				v.MountPoint = space.MountPoint
+				v.PurgeableBytes = space.PurgeableSpace
+			}
+		}
+	}
+
+	return &Usage{Thresholds: t, Containers: containers}, nil
+}
+
+func parseAPFSList(data []byte, t Thresholds) ([]APFSContainer, error) {
+	var list diskutilAPFSList
+	if err := plist.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("failed to parse diskutil apfs list: %w", err)
+	}
+
+	var containers []APFSContainer
+	for _, c := range list.Containers {
+		ct := APFSContainer{
+			Device:        c.ContainerReference,
+			UUID:          c.APFSContainerUUID,
+			PhysicalStore: c.DesignatedPhysicalStore,
+			CapacityBytes: c.CapacityCeiling,
+			FreeBytes:     c.CapacityFree,
+			UsedBytes:     max(c.CapacityCeiling-c.CapacityFree, 0),
+			Volumes:       []APFSVolume{},
+		}
+		if ct.CapacityBytes > 0 {
+			ct.FreePercent = float64(ct.FreeBytes) / float64(ct.CapacityBytes) * 100
+			ct.Status = t.Status(ct.FreePercent)
+		} else {
+			ct.Status = SpaceUnknown
+		}
+
+		for _, v := range c.Volumes {
+			vol := APFSVolume{
+				Device:       v.DeviceIdentifier,
+				Name:         v.Name,
+				Roles:        v.Roles,
+				UsedBytes:    v.CapacityInUse,
+				QuotaBytes:   v.CapacityQuota,
+				ReserveBytes: v.CapacityReserve,
+				Encrypted:    v.Encryption,
+				FileVault:    v.FileVault,
+				Locked:       v.Locked,
+			}
+			if len(v.Roles) > 0 {
+				vol.Role = v.Roles[0]
+			}
+			ct.Volumes = append(ct.Volumes, vol)
+		}
+
+		containers = append(containers, ct)
+	}
+
+	return containers, nil
+}
@@ -0,0 +1,159 @@
+package disk
+
+import "testing"
+
+const apfsListPlist = `<?xml version="1.0" encoding="UTF-8"?>
+<plist version="1.0">
+<dict>
+	<key>Containers</key>
+	<array>
+		<dict>
+			<key>APFSContainerUUID</key>
+			<string>5E1D2C9A-0000-0000-0000-000000000001</string>
+			<key>CapacityCeiling</key>
+			<integer>494384795648</integer>
+			<key>CapacityFree</key>
+			<integer>49438479564</integer>
+			<key>ContainerReference</key>
+			<string>disk3</string>
+			<key>DesignatedPhysicalStore</key>
+			<string>disk0s2</string>
+			<key>Volumes</key>
+			<array>
+				<dict>
+					<key>CapacityInUse</key>
+					<integer>11000000000</integer>
+					<key>DeviceIdentifier</key>
+					<string>disk3s1</string>
+					<key>Encryption</key>
+					<false/>
+					<key>FileVault</key>
+					<false/>
+					<key>Locked</key>
+					<false/>
+					<key>Name</key>
+					<string>Macintosh HD</string>
+					<key>Roles</key>
+					<array>
+						<string>System</string>
+					</array>
+				</dict>
+				<dict>
+					<key>CapacityInUse</key>
+					<integer>420000000000</integer>
+					<key>DeviceIdentifier</key>
+					<string>disk3s5</string>
+					<key>Encryption</key>
+					<true/>
+					<key>FileVault</key>
+					<true/>
+					<key>Name</key>
+					<string>Macintosh HD - Data</string>
+					<key>Roles</key>
+					<array>
+						<string>Data</string>
+					</array>
+				</dict>
+				<dict>
+					<key>CapacityInUse</key>
+					<integer>1073741824</integer>
+					<key>DeviceIdentifier</key>
+					<string>disk3s6</string>
+					<key>Name</key>
+					<string>VM</string>
+					<key>Roles</key>
+					<array>
+						<string>VM</string>
+					</array>
+				</dict>
+			</array>
+		</dict>
+		<dict>
+			<key>CapacityCeiling</key>
+			<integer>1000000000000</integer>
+			<key>CapacityFree</key>
+			<integer>20000000000</integer>
+			<key>ContainerReference</key>
+			<string>disk5</string>
+			<key>Volumes</key>
+			<array>
+				<dict>
+					<key>DeviceIdentifier</key>
+					<string>disk5s1</string>
+					<key>Name</key>
+					<string>Backup</string>
+					<key>Roles</key>
+					<array/>
+				</dict>
+			</array>
+		</dict>
+	</array>
+</dict>
+</plist>
+`
+
+func TestParseAPFSList(t *testing.T) {
+	containers, err := parseAPFSList([]byte(apfsListPlist), Thresholds{LowPercent: 15, CriticalPercent: 5})
+	if err != nil {
+		t.Fatalf("parseAPFSList: %v", err)
+	}
+	if len(containers) != 2 {
+		t.Fatalf("expected 2 containers, got %d", len(containers))
+	}
+
+	c := containers[0]
+	if c.Device != "disk3" || c.PhysicalStore != "disk0s2" || c.UsedBytes != 494384795648-49438479564 {
+		t.Errorf("container = %+v", c)
+	}
+	if c.Status != SpaceLow {
+		t.Errorf("Status = %q at %.1f%% free, want %q", c.Status, c.FreePercent, SpaceLow)
+	}
+	if len(c.Volumes) != 3 {
+		t.Fatalf("expected 3 volumes, got %+v", c.Volumes)
+	}
+	data := c.Volumes[1]
+	if data.Role != "Data" || !data.Encrypted || !data.FileVault || data.UsedBytes != 420000000000 {
+		t.Errorf("data volume = %+v", data)
+	}
+
+	external := containers[1]
+	if external.Status != SpaceCritical || external.Volumes[0].Role != "" {
+		t.Errorf("external container = %+v", external)
+	}
+}
+
+func TestThresholdsStatus(t *testing.T) {
+	th := Thresholds{LowPercent: 15, CriticalPercent: 5}
+	tests := map[float64]string{
+		50:  SpaceOK,
+		15:  SpaceOK,
+		14:  SpaceLow,
+		4.9: SpaceCritical,
+		0:   SpaceCritical,
+	}
+	for free, want := range tests {
+		if got := th.Status(free); got != want {
+			t.Errorf("Status(%v) = %q, want %q", free, got, want)
+		}
+	}
+}
+
+func TestParseAPFSListZeroCapacity(t *testing.T) {
+	input := `<plist><dict><key>Containers</key><array><dict>
+		<key>ContainerReference</key><string>disk9</string>
+		<key>CapacityCeiling</key><integer>0</integer>
+		<key>CapacityFree</key><integer>4096</integer>
+	</dict></array></dict></plist>`
+
+	containers, err := parseAPFSList([]byte(input), Thresholds{LowPercent: 15, CriticalPercent: 5})
+	if err != nil {
+		t.Fatalf("parseAPFSList: %v", err)
+	}
+	if len(containers) != 1 {
+		t.Fatalf("expected 1 container, got %d", len(containers))
+	}
+	c := containers[0]
+	if c.Status != SpaceUnknown || c.UsedBytes != 0 || c.FreePercent != 0 {
+		t.Errorf("container = %+v, want status %q with nothing used", c, SpaceUnknown)
+	}
+}
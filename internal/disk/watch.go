@@ -0,0 +1,147 @@
+package disk
+
+import (
+	"context"
+	"math"
+	"sort"
+	"time"
+)
+
+// Distribution summarizes a series of samples.
+type Distribution struct {
+	Min float64 `json:"min"`
+	Avg float64 `json:"avg"`
+	Max float64 `json:"max"`
+	P95 float64 `json:"p95"`
+}
+
+// IOSummary summarizes a device's I/O samples over a watch session.
+type IOSummary struct {
+	Device         string       `json:"device"`
+	Samples        int          `json:"samples"`
+	ReadMBs        Distribution `json:"read_mbs"`
+	WriteMBs       Distribution `json:"write_mbs"`
+	ReadIOPS       Distribution `json:"read_iops"`
+	WriteIOPS      Distribution `json:"write_iops"`
+	ReadLatencyMS  Distribution `json:"read_latency_ms"`
+	WriteLatencyMS Distribution `json:"write_latency_ms"`
+}
+
+// IOTracker accumulates I/O samples per device.
+type IOTracker struct {
+	devices []string
+	samples map[string][]IOStats
+}
+
+// NewIOTracker returns an empty IOTracker.
+func NewIOTracker() *IOTracker {
+	return &IOTracker{samples: make(map[string][]IOStats)}
+}
+
+// Add records a round of samples.
+func (t *IOTracker) Add(stats []IOStats) {
+	for _, s := range stats {
+		if _, ok := t.samples[s.Device]; !ok {
+			t.devices = append(t.devices, s.Device)
+		}
+		t.samples[s.Device] = append(t.samples[s.Device], s)
+	}
+}
+
+// Summary returns min/avg/max/p95 of each metric per device, in the order
+// devices were first seen.
+func (t *IOTracker) Summary() []IOSummary {
+	var out []IOSummary
+	for _, dev := range t.devices {
+		samples := t.samples[dev]
+		field := func(f func(IOStats) float64) Distribution {
+			values := make([]float64, len(samples))
+			for i, s := range samples {
+				values[i] = f(s)
+			}
+			return distribution(values)
+		}
+
+		out = append(out, IOSummary{
+			Device:         dev,
+			Samples:        len(samples),
+			ReadMBs:        field(func(s IOStats) float64 { return s.ReadMBs }),
+			WriteMBs:       field(func(s IOStats) float64 { return s.WriteMBs }),
+			ReadIOPS:       field(func(s IOStats) float64 { return s.ReadIOPS }),
+			WriteIOPS:      field(func(s IOStats) float64 { return s.WriteIOPS }),
+			ReadLatencyMS:  field(func(s IOStats) float64 { return s.ReadLatencyMS }),
+			WriteLatencyMS: field(func(s IOStats) float64 { return s.WriteLatencyMS }),
+		})
+	}
+	return out
+}
+
+// WatchIO samples I/O counters every interval until ctx is cancelled,
+// calling fn with each round's rates for the given devices (or every disk).
+func WatchIO(ctx context.Context, interval time.Duration, devices []string, fn func([]IOStats)) error {
+	if interval <= 0 {
+		interval = DefaultIOInterval
+	}
+
+	prev, err := ReadIOCounters()
+	if err != nil {
+		return err
+	}
+	prevAt := time.Now()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		cur, err := ReadIOCounters()
+		if err != nil {
+			return err
+		}
+		now := time.Now()
+
+		stats, err := diffIOCounters(prev, cur, now.Sub(prevAt), devices)
+		if err != nil {
+			return err
+		}
+		for i := range stats {
+			stats[i].Timestamp = now.UTC()
+		}
+		fn(stats)
+
+		prev, prevAt = cur, now
+	}
+}
+
+// distribution returns the min, mean, max and nearest-rank 95th percentile
+// of values.
+func distribution(values []float64) Distribution {
+	if len(values) == 0 {
+		return Distribution{}
+	}
+
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+
+	var sum float64
+	for _, v := range sorted {
+		sum += v
+	}
+
+	rank := int(math.Ceil(0.95*float64(len(sorted)))) - 1
+	if rank < 0 {
+		rank = 0
+	}
+
+	return Distribution{
+		Min: sorted[0],
+		Avg: sum / float64(len(sorted)),
+		Max: sorted[len(sorted)-1],
+		P95: sorted[rank],
+	}
+}
@@ -0,0 +1,54 @@
+package disk
+
+import "testing"
+
+func TestDistribution(t *testing.T) {
+	values := make([]float64, 20)
+	for i := range values {
+		values[i] = float64(20 - i) // 20, 19, ..., 1
+	}
+
+	got := distribution(values)
+	want := Distribution{Min: 1, Avg: 10.5, Max: 20, P95: 19}
+	if got != want {
+		t.Errorf("distribution = %+v, want %+v", got, want)
+	}
+
+	if got := distribution(nil); got != (Distribution{}) {
+		t.Errorf("distribution(nil) = %+v, want zero", got)
+	}
+	if got := distribution([]float64{3}); got != (Distribution{Min: 3, Avg: 3, Max: 3, P95: 3}) {
+		t.Errorf("distribution([3]) = %+v", got)
+	}
+}
+
+func TestIOTrackerSummary(t *testing.T) {
+	tr := NewIOTracker()
+	tr.Add([]IOStats{
+		{Device: "disk4", ReadMBs: 10, WriteLatencyMS: 2},
+		{Device: "disk0", ReadMBs: 1, WriteIOPS: 100},
+	})
+	tr.Add([]IOStats{
+		{Device: "disk4", ReadMBs: 30, WriteLatencyMS: 4},
+		{Device: "disk0", ReadMBs: 3, WriteIOPS: 300},
+	})
+
+	summary := tr.Summary()
+	if len(summary) != 2 {
+		t.Fatalf("expected 2 devices, got %d", len(summary))
+	}
+	if summary[0].Device != "disk4" || summary[1].Device != "disk0" {
+		t.Errorf("device order = %s, %s; want first-seen order", summary[0].Device, summary[1].Device)
+	}
+
+	disk4 := summary[0]
+	if disk4.Samples != 2 || disk4.ReadMBs != (Distribution{Min: 10, Avg: 20, Max: 30, P95: 30}) {
+		t.Errorf("disk4 read = %+v", disk4.ReadMBs)
+	}
+	if disk4.WriteLatencyMS.Avg != 3 {
+		t.Errorf("disk4 write latency avg = %v, want 3", disk4.WriteLatencyMS.Avg)
+	}
+	if summary[1].WriteIOPS.Max != 300 {
+		t.Errorf("disk0 write IOPS max = %v, want 300", summary[1].WriteIOPS.Max)
+	}
+}
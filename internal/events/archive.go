@@ -0,0 +1,285 @@
+package events
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+)
+
+const (
+	// DefaultArchiveBackfill is how far back the first archive run reads.
+	// The unified log rarely retains more than this.
+	DefaultArchiveBackfill = 7 * 24 * time.Hour
+
+	// MaxArchiveAge is how long archived events are kept.
+	MaxArchiveAge = 365 * 24 * time.Hour
+
+	archiveFileName = "events-archive.json"
+)
+
+// ErrCorruptArchive is wrapped by LoadArchive when the archive file is not
+// valid JSON.
+var ErrCorruptArchive = errors.New("events archive is corrupt")
+
+// ArchiveError is returned by CollectArchivedEvents, together with the events
+// read from the live log, when the archive could not be loaded.
+type ArchiveError struct {
+	Err error
+}
+
+func (e *ArchiveError) Error() string {
+	return e.Err.Error() + "; reading events from the system log only"
+}
+
+func (e *ArchiveError) Unwrap() error {
+	return e.Err
+}
+
+// Archive holds classified events persisted beyond the unified log's
+// retention. HighWater is the end of the last archived log window.
+type Archive struct {
+	HighWater time.Time `json:"high_water"`
+	Events    []Event   `json:"events"`
+}
+
+// ArchiveResult describes one archive update.
+type ArchiveResult struct {
+	Since     time.Time `json:"since"`
+	HighWater time.Time `json:"high_water"`
+	Added     int       `json:"added"`
+	Total     int       `json:"total"`
+
+	// Corrupt is where an unreadable archive was moved before this update
+	// started a new one.
+	Corrupt string `json:"corrupt,omitempty"`
+}
+
+// archivePath returns the path to the events archive file.
+func archivePath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(home, ".config", "macctl", archiveFileName), nil
+}
+
+// LoadArchive reads the events archive. A missing file is an empty archive.
+func LoadArchive() (*Archive, error) {
+	path, err := archivePath()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &Archive{}, nil
+		}
+		return nil, fmt.Errorf("failed to read events archive: %w", err)
+	}
+
+	var a Archive
+	if err := json.Unmarshal(data, &a); err != nil {
+		return nil, fmt.Errorf("failed to parse events archive: %w: %w", ErrCorruptArchive, err)
+	}
+	return &a, nil
+}
+
+// SaveArchive writes the events archive, dropping events older than
+// MaxArchiveAge.
+func SaveArchive(a *Archive) error {
+	cutoff := time.Now().UTC().Add(-MaxArchiveAge)
+	kept := a.Events[:0]
+	for _, e := range a.Events {
+		if e.Timestamp.After(cutoff) {
+			kept = append(kept, e)
+		}
+	}
+	a.Events = kept
+
+	path, err := archivePath()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(a, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal events archive: %w", err)
+	}
+
+	if err := writeFileAtomic(path, data); err != nil {
+		return fmt.Errorf("failed to write events archive: %w", err)
+	}
+	return nil
+}
+
+// writeFileAtomic writes data to a temporary file next to path and renames
+// it into place, so a crash mid-write never leaves a truncated file.
+func writeFileAtomic(path string, data []byte) error {
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+	defer os.Remove(tmp)
+
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// rotateCorruptArchive moves an unparseable archive aside so the next update
+// can start a new one. It returns the new path.
+func rotateCorruptArchive() (string, error) {
+	path, err := archivePath()
+	if err != nil {
+		return "", err
+	}
+	dest := path + ".corrupt-" + time.Now().UTC().Format("20060102T150405Z")
+	if err := os.Rename(path, dest); err != nil {
+		return "", fmt.Errorf("failed to move corrupt events archive aside: %w", err)
+	}
+	return dest, nil
+}
+
+// UpdateArchive classifies log entries from the archive's high-water mark (or
+// DefaultArchiveBackfill ago, on the first run) up to now with every source
+// and the user's rules, and appends them to the archive. A corrupt archive is
+// moved aside and rebuilt from the backfill window.
+func UpdateArchive() (*ArchiveResult, error) {
+	var corrupt string
+	a, err := LoadArchive()
+	if errors.Is(err, ErrCorruptArchive) {
+		if corrupt, err = rotateCorruptArchive(); err == nil {
+			a = &Archive{}
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := LoadClassifier(SourceNames())
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	since := a.HighWater
+	if since.IsZero() {
+		since = now.Add(-DefaultArchiveBackfill)
+	}
+
+	result := &ArchiveResult{Since: since, HighWater: a.HighWater, Corrupt: corrupt}
+	if !since.Before(now) {
+		result.Total = len(a.Events)
+		return result, nil
+	}
+
+	fresh, err := CollectEvents(Query{Since: since, Until: now, Classifier: c})
+	if err != nil {
+		return nil, err
+	}
+
+	before := len(a.Events)
+	a.Events = MergeEvents(a.Events, fresh)
+	a.HighWater = now
+	if err := SaveArchive(a); err != nil {
+		return nil, err
+	}
+
+	result.HighWater = a.HighWater
+	result.Added = len(a.Events) - before
+	result.Total = len(a.Events)
+	return result, nil
+}
+
+// CollectArchivedEvents returns the events matching q from both the archive
+// and the live log. Archived events cover the window up to the archive's
+// high-water mark, so only the rest is read from the log. If the archive
+// can't be loaded, the whole window is read from the log and the events are
+// returned with an *ArchiveError.
+func CollectArchivedEvents(q Query) ([]Event, error) {
+	a, err := LoadArchive()
+	if err != nil {
+		found, liveErr := CollectEvents(q)
+		if liveErr != nil {
+			return nil, liveErr
+		}
+		return found, &ArchiveError{Err: err}
+	}
+
+	if q.Until.IsZero() {
+		q.Until = time.Now()
+	}
+
+	var archived []Event
+	for _, e := range a.Events {
+		if e.Timestamp.Before(q.Since) || e.Timestamp.After(q.Until) {
+			continue
+		}
+		if q.Classifier.Selects(e) {
+			archived = append(archived, e)
+		}
+	}
+
+	live := q
+	if a.HighWater.After(live.Since) {
+		live.Since = a.HighWater
+	}
+	if !live.Since.Before(live.Until) {
+		return archived, nil
+	}
+
+	fresh, err := CollectEvents(live)
+	if err != nil {
+		return nil, err
+	}
+	return MergeEvents(archived, fresh), nil
+}
+
+// MergeEvents combines two event lists in timestamp order, dropping events
+// that appear in both.
+func MergeEvents(a, b []Event) []Event {
+	seen := make(map[string]bool, len(a)+len(b))
+	merged := make([]Event, 0, len(a)+len(b))
+	for _, list := range [][]Event{a, b} {
+		for _, e := range list {
+			key := eventKey(e)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			merged = append(merged, e)
+		}
+	}
+
+	sort.SliceStable(merged, func(i, j int) bool {
+		return merged[i].Timestamp.Before(merged[j].Timestamp)
+	})
+	return merged
+}
+
+func eventKey(e Event) string {
+	return strconv.FormatInt(e.Timestamp.UnixNano(), 10) + "\x00" + e.Source + "\x00" + e.Type + "\x00" + e.Message
+}
@@ -0,0 +1,179 @@
+package events
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestMergeEvents(t *testing.T) {
+	base := time.Date(2025, 1, 15, 8, 0, 0, 0, time.UTC)
+	wake := Event{Timestamp: base, Source: SourcePower, Type: EventWake, Message: "Wake Reason: EC.LidOpen"}
+	sleep := Event{Timestamp: base.Add(time.Hour), Source: SourcePower, Type: EventSleep, Message: "Entering Sleep"}
+	display := Event{Timestamp: base.Add(30 * time.Minute), Source: SourceDisplay, Type: EventDisplayConnect, Message: "Display added"}
+
+	got := MergeEvents([]Event{wake, sleep}, []Event{display, sleep})
+	if len(got) != 3 {
+		t.Fatalf("expected 3 events, got %d: %+v", len(got), got)
+	}
+	wantTypes := []string{EventWake, EventDisplayConnect, EventSleep}
+	for i, want := range wantTypes {
+		if got[i].Type != want {
+			t.Errorf("event %d Type = %q, want %q", i, got[i].Type, want)
+		}
+	}
+
+	// Same time and type but a different message is a distinct event.
+	other := wake
+	other.Message = "Wake Reason: RTC"
+	if got := MergeEvents([]Event{wake}, []Event{other}); len(got) != 2 {
+		t.Errorf("expected distinct messages to be kept, got %d events", len(got))
+	}
+}
+
+func TestClassifierSelects(t *testing.T) {
+	c, err := NewClassifier([]Rule{{Name: "usb_attach", Match: "USB"}}, []string{SourceAudio})
+	if err != nil {
+		t.Fatalf("NewClassifier: %v", err)
+	}
+
+	tests := []struct {
+		event Event
+		want  bool
+	}{
+		{Event{Source: SourceAudio, Type: EventAudioDeviceAdded}, true},
+		{Event{Source: SourcePower, Type: EventWake}, false},
+		{Event{Source: SourceCustom, Type: "usb_attach"}, true},
+		{Event{Source: SourceCustom, Type: "removed_rule"}, false},
+	}
+
+	for _, tt := range tests {
+		if got := c.Selects(tt.event); got != tt.want {
+			t.Errorf("Selects(%s/%s) = %v, want %v", tt.event.Source, tt.event.Type, got, tt.want)
+		}
+	}
+
+	var defaults *Classifier
+	if !defaults.Selects(Event{Source: SourcePower, Type: EventSleep}) {
+		t.Error("nil classifier should select power events")
+	}
+}
+
+func TestArchiveRoundTrip(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	a, err := LoadArchive()
+	if err != nil {
+		t.Fatalf("LoadArchive on missing file: %v", err)
+	}
+	if !a.HighWater.IsZero() || len(a.Events) != 0 {
+		t.Fatalf("expected empty archive, got %+v", a)
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	a.HighWater = now
+	a.Events = []Event{
+		{Timestamp: now.Add(-MaxArchiveAge - time.Hour), Source: SourcePower, Type: EventWake},
+		{Timestamp: now.Add(-time.Hour), Source: SourcePower, Type: EventSleep},
+	}
+	if err := SaveArchive(a); err != nil {
+		t.Fatalf("SaveArchive: %v", err)
+	}
+
+	got, err := LoadArchive()
+	if err != nil {
+		t.Fatalf("LoadArchive: %v", err)
+	}
+	if !got.HighWater.Equal(now) {
+		t.Errorf("HighWater = %v, want %v", got.HighWater, now)
+	}
+	if len(got.Events) != 1 || got.Events[0].Type != EventSleep {
+		t.Errorf("expected only the recent sleep event to be kept, got %+v", got.Events)
+	}
+
+	// The write goes through a temp file that is renamed into place.
+	entries, err := os.ReadDir(filepath.Join(os.Getenv("HOME"), ".config", "macctl"))
+	if err != nil {
+		t.Fatalf("ReadDir: %v", err)
+	}
+	if len(entries) != 1 || entries[0].Name() != archiveFileName {
+		t.Errorf("config dir entries = %v, want only %s", entries, archiveFileName)
+	}
+}
+
+func TestCollectArchivedEventsCorruptArchive(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	dir := filepath.Join(home, ".config", "macctl")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, archiveFileName), []byte(`{"high_water": "2025-01`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	since := time.Date(2025, 1, 15, 8, 0, 0, 0, time.UTC)
+	live := Event{Timestamp: since.Add(time.Hour), Source: SourcePower, Type: EventWake}
+
+	orig := runPage
+	defer func() { runPage = orig }()
+	var queried time.Time
+	runPage = func(_ *Classifier, start, _ time.Time, fn func(Event)) error {
+		queried = start
+		fn(live)
+		return nil
+	}
+
+	got, err := CollectArchivedEvents(Query{Since: since, Until: since.Add(2 * time.Hour)})
+	var archErr *ArchiveError
+	if !errors.As(err, &archErr) || !errors.Is(err, ErrCorruptArchive) {
+		t.Fatalf("CollectArchivedEvents with corrupt archive: err = %v, want *ArchiveError", err)
+	}
+	if !queried.Equal(since) {
+		t.Errorf("live query started at %v, want the full window from %v", queried, since)
+	}
+	if len(got) != 1 || !got[0].Timestamp.Equal(live.Timestamp) {
+		t.Errorf("events = %+v, want the live event", got)
+	}
+}
+
+func TestUpdateArchiveRotatesCorruptArchive(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	dir := filepath.Join(home, ".config", "macctl")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, archiveFileName), []byte(`{"events": [`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	live := Event{Timestamp: time.Now().UTC().Add(-time.Hour), Source: SourcePower, Type: EventWake}
+	orig := runPage
+	defer func() { runPage = orig }()
+	runPage = func(_ *Classifier, _, _ time.Time, fn func(Event)) error {
+		fn(live)
+		return nil
+	}
+
+	result, err := UpdateArchive()
+	if err != nil {
+		t.Fatalf("UpdateArchive with corrupt archive: %v", err)
+	}
+	if result.Corrupt == "" || result.Added != 1 {
+		t.Errorf("result = %+v, want the corrupt file moved aside and 1 event added", result)
+	}
+	if _, err := os.Stat(result.Corrupt); err != nil {
+		t.Errorf("corrupt archive not kept: %v", err)
+	}
+
+	a, err := LoadArchive()
+	if err != nil {
+		t.Fatalf("LoadArchive after rebuild: %v", err)
+	}
+	if len(a.Events) != 1 {
+		t.Errorf("rebuilt archive has %d events, want 1", len(a.Events))
+	}
+}
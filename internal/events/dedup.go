@@ -0,0 +1,142 @@
+package events
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Dedup keys select which events Deduplicate treats as repeats.
+const (
+	DedupByType   = "type"
+	DedupByReason = "type+reason"
+)
+
+const (
+	// DefaultDedupWindow is the default window for collapsing repeats.
+	DefaultDedupWindow = 30 * time.Second
+
+	// DefaultDedupSamples is the default number of distinct details kept
+	// for a collapsed event.
+	DefaultDedupSamples = 3
+)
+
+// DedupOptions controls Deduplicate.
+type DedupOptions struct {
+	// Window is the longest span, measured from the first event of a run,
+	// over which repeats are collapsed.
+	Window time.Duration
+
+	// By is DedupByType or DedupByReason. Empty means DedupByType.
+	By string
+
+	// MaxSamples caps the distinct details kept per collapsed event.
+	// Zero means DefaultDedupSamples.
+	MaxSamples int
+}
+
+// ParseDedupWindow parses a --dedup value such as "30s" or "5m". Zero
+// collapses only events with identical timestamps.
+func ParseDedupWindow(s string) (time.Duration, error) {
+	window, err := time.ParseDuration(strings.TrimSpace(s))
+	if err != nil {
+		return 0, fmt.Errorf("invalid dedup window: %w", err)
+	}
+	if window < 0 {
+		return 0, fmt.Errorf("invalid dedup window %q: must not be negative", s)
+	}
+	return window, nil
+}
+
+// ParseDedupKey parses a --dedup-by value: "type", or "reason" /
+// "type+reason".
+func ParseDedupKey(s string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", DedupByType:
+		return DedupByType, nil
+	case "reason", DedupByReason:
+		return DedupByReason, nil
+	default:
+		return "", fmt.Errorf("invalid dedup key %q (use type or type+reason)", s)
+	}
+}
+
+// Deduplicate collapses consecutive repeats of an event within opts.Window
+// into a single event. The result keeps the first event's fields and
+// records the run's count, first and last timestamps, and a sample of its
+// distinct details.
+func Deduplicate(events []Event, opts DedupOptions) []Event {
+	if len(events) == 0 {
+		return nil
+	}
+	if opts.MaxSamples <= 0 {
+		opts.MaxSamples = DefaultDedupSamples
+	}
+
+	var result []Event
+	current := startRun(events[0])
+	currentKey := dedupKey(events[0], opts.By)
+
+	for _, e := range events[1:] {
+		key := dedupKey(e, opts.By)
+		if key == currentKey && e.Timestamp.Sub(current.FirstSeen) <= opts.Window {
+			current.Count++
+			current.LastSeen = e.Timestamp
+			addSample(&current, e.Detail, opts.MaxSamples)
+			continue
+		}
+
+		result = append(result, finishRun(current))
+		current = startRun(e)
+		currentKey = key
+	}
+	result = append(result, finishRun(current))
+
+	return result
+}
+
+// DeduplicateEvents collapses consecutive events of the same type within
+// a time window into a single event with a count.
+func DeduplicateEvents(events []Event, window time.Duration) []Event {
+	return Deduplicate(events, DedupOptions{Window: window})
+}
+
+func dedupKey(e Event, by string) string {
+	key := e.Source + "\x00" + e.Type
+	if by == DedupByReason {
+		reason := e.ReasonCategory
+		if reason == "" {
+			reason = e.Reason
+		}
+		key += "\x00" + reason
+	}
+	return key
+}
+
+func startRun(e Event) Event {
+	e.Count = 1
+	e.FirstSeen = e.Timestamp
+	e.LastSeen = e.Timestamp
+	e.Samples = []string{e.Detail}
+	return e
+}
+
+func addSample(e *Event, detail string, max int) {
+	if len(e.Samples) >= max {
+		return
+	}
+	for _, s := range e.Samples {
+		if s == detail {
+			return
+		}
+	}
+	e.Samples = append(e.Samples, detail)
+}
+
+// finishRun drops the sample list when it holds nothing beyond Detail.
+func finishRun(e Event) Event {
+	if len(e.Samples) < 2 {
+		e.Samples = nil
+	}
+	return e
+}
@@ -0,0 +1,142 @@
+package events
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDeduplicate(t *testing.T) {
+	base := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+
+	t.Run("first and last seen with samples", func(t *testing.T) {
+		got := Deduplicate([]Event{
+			{Timestamp: base, Type: EventPowerSource, Detail: "update 1"},
+			{Timestamp: base.Add(5 * time.Second), Type: EventPowerSource, Detail: "update 2"},
+			{Timestamp: base.Add(10 * time.Second), Type: EventPowerSource, Detail: "update 1"},
+			{Timestamp: base.Add(20 * time.Second), Type: EventPowerSource, Detail: "update 3"},
+		}, DedupOptions{Window: 30 * time.Second, MaxSamples: 2})
+
+		if len(got) != 1 {
+			t.Fatalf("expected 1 event, got %d", len(got))
+		}
+		e := got[0]
+		if e.Count != 4 {
+			t.Errorf("Count = %d, want 4", e.Count)
+		}
+		if !e.FirstSeen.Equal(base) || !e.LastSeen.Equal(base.Add(20*time.Second)) {
+			t.Errorf("FirstSeen/LastSeen = %v/%v", e.FirstSeen, e.LastSeen)
+		}
+		if want := []string{"update 1", "update 2"}; !reflect.DeepEqual(e.Samples, want) {
+			t.Errorf("Samples = %v, want %v", e.Samples, want)
+		}
+	})
+
+	t.Run("identical details keep no samples", func(t *testing.T) {
+		got := Deduplicate([]Event{
+			{Timestamp: base, Type: EventWake, Detail: "wake"},
+			{Timestamp: base.Add(time.Second), Type: EventWake, Detail: "wake"},
+		}, DedupOptions{Window: time.Minute})
+
+		if len(got) != 1 || got[0].Samples != nil {
+			t.Errorf("expected one event without samples, got %+v", got)
+		}
+	})
+
+	t.Run("window measured from first event", func(t *testing.T) {
+		got := Deduplicate([]Event{
+			{Timestamp: base, Type: EventWake},
+			{Timestamp: base.Add(20 * time.Second), Type: EventWake},
+			{Timestamp: base.Add(40 * time.Second), Type: EventWake},
+		}, DedupOptions{Window: 30 * time.Second})
+
+		if len(got) != 2 || got[0].Count != 2 || got[1].Count != 1 {
+			t.Errorf("expected runs of 2 and 1, got %+v", got)
+		}
+	})
+
+	t.Run("zero window keeps distinct timestamps", func(t *testing.T) {
+		got := Deduplicate([]Event{
+			{Timestamp: base, Type: EventWake},
+			{Timestamp: base.Add(time.Second), Type: EventWake},
+		}, DedupOptions{})
+
+		if len(got) != 2 {
+			t.Errorf("expected 2 events, got %d", len(got))
+		}
+	})
+
+	t.Run("keyed by reason", func(t *testing.T) {
+		events := []Event{
+			{Timestamp: base, Type: EventWake, ReasonCategory: ReasonLid},
+			{Timestamp: base.Add(time.Second), Type: EventWake, ReasonCategory: ReasonRTC},
+			{Timestamp: base.Add(2 * time.Second), Type: EventWake, ReasonCategory: ReasonRTC},
+		}
+
+		byType := Deduplicate(events, DedupOptions{Window: time.Minute, By: DedupByType})
+		if len(byType) != 1 || byType[0].Count != 3 {
+			t.Errorf("by type: expected one run of 3, got %+v", byType)
+		}
+
+		byReason := Deduplicate(events, DedupOptions{Window: time.Minute, By: DedupByReason})
+		if len(byReason) != 2 || byReason[0].Count != 1 || byReason[1].Count != 2 {
+			t.Errorf("by reason: expected runs of 1 and 2, got %+v", byReason)
+		}
+	})
+}
+
+func TestParseDedupKey(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{input: "", want: DedupByType},
+		{input: "type", want: DedupByType},
+		{input: "reason", want: DedupByReason},
+		{input: "Type+Reason", want: DedupByReason},
+		{input: "detail", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseDedupKey(tt.input)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ParseDedupKey(%q) expected error", tt.input)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("ParseDedupKey(%q) = %q, %v; want %q", tt.input, got, err, tt.want)
+		}
+	}
+}
+
+func TestParseDedupWindow(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{input: "30s", want: 30 * time.Second},
+		{input: "5m", want: 5 * time.Minute},
+		{input: "0", want: 0},
+		{input: DefaultDedupWindow.String(), want: DefaultDedupWindow},
+		{input: "-1s", wantErr: true},
+		{input: "1d", wantErr: true},
+		{input: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseDedupWindow(tt.input)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ParseDedupWindow(%q) expected error", tt.input)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("ParseDedupWindow(%q) = %v, %v; want %v", tt.input, got, err, tt.want)
+		}
+	}
+}
@@ -1,54 +1,71 @@
 package events
 
 import (
+	"encoding/json"
 	"fmt"
-	"os/exec"
+	"path"
 	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/lu-zhengda/macctl/internal/power"
 )
 
-// PowerEvent represents a power-related system event.
-type PowerEvent struct {
+// Event represents a classified system event.
+type Event struct {
 	Timestamp time.Time `json:"timestamp"`
+	Source    string    `json:"source,omitempty"`
 	Type      string    `json:"type"`
 	Detail    string    `json:"detail"`
 	Count     int       `json:"count,omitempty"`
+	Process   string    `json:"process,omitempty"`
+	PID       int       `json:"pid,omitempty"`
+	Subsystem string    `json:"subsystem,omitempty"`
+	Category  string    `json:"category,omitempty"`
+	Message   string    `json:"message,omitempty"`
+
+	// Fields holds values extracted by a user-defined rule.
+	Fields map[string]string `json:"fields,omitempty"`
+
+	// Reason and ReasonCategory are set for wake and sleep events.
+	Reason         string `json:"reason,omitempty"`
+	ReasonCategory string `json:"reason_category,omitempty"`
+
+	// FirstSeen, LastSeen and Samples describe events collapsed by Deduplicate.
+	FirstSeen time.Time `json:"first_seen,omitzero"`
+	LastSeen  time.Time `json:"last_seen,omitzero"`
+	Samples   []string  `json:"samples,omitempty"`
 }
 
 // EventType constants for categorizing events.
 const (
-	EventWake          = "wake"
-	EventSleep         = "sleep"
-	EventLidOpen       = "lid_open"
-	EventLidClose      = "lid_close"
-	EventThermal       = "thermal_throttle"
-	EventPowerSource   = "power_source_change"
-	EventPowerUnknown  = "power_event"
+	EventWake         = "wake"
+	EventSleep        = "sleep"
+	EventLidOpen      = "lid_open"
+	EventLidClose     = "lid_close"
+	EventThermal      = "thermal_throttle"
+	EventPowerSource  = "power_source_change"
+	EventPowerUnknown = "power_event"
 )
 
-// GetEvents queries the system log for power-related events.
-func GetEvents(lastDuration string) ([]PowerEvent, error) {
+// GetEvents queries the system log for power-related events over the last
+// duration (e.g., "24h", "7d").
+func GetEvents(lastDuration string) ([]Event, error) {
 	if lastDuration == "" {
 		lastDuration = "24h"
 	}
 
-	out, err := exec.Command("log", "show",
-		"--predicate", `subsystem == "com.apple.powerd"`,
-		"--style", "compact",
-		"--last", lastDuration,
-	).Output()
+	d, err := ParseDuration(lastDuration)
 	if err != nil {
-		return nil, fmt.Errorf("failed to query system log: %w", err)
+		return nil, err
 	}
 
-	return parseLogOutput(string(out)), nil
+	return CollectArchivedEvents(LastQuery(d))
 }
 
-func parseLogOutput(output string) []PowerEvent {
-	var events []PowerEvent
+func parseLogOutput(output string) []Event {
+	var events []Event
 
 	lines := strings.Split(output, "\n")
 	for _, line := range lines {
@@ -70,12 +87,77 @@ func parseLogOutput(output string) []PowerEvent {
 // Format: "Df powerd[323:dbd3ca] [com.apple.powerd:battery] " (type + process + subsystem).
 var logPrefixRe = regexp.MustCompile(`^\w{1,3}\s+.+?\[[^\]]+\]\s+\[[^\]]+\]\s+`)
 
+// compactPrefixRe captures the fields of the compact log prefix:
+// message type, process, PID, subsystem, and optional category.
+var compactPrefixRe = regexp.MustCompile(`^(\w{1,3})\s+(.+?)\[(\d+):[^\]]*\]\s+\[([^\]:]+)(?::([^\]]*))?\]\s+`)
+
 // timestampRe matches the compact log timestamp format.
 // Real compact format: "2025-01-15 10:30:45.123" (no timezone offset).
 // Also supports legacy format with timezone: "2025-01-15 10:30:45.123456-0800".
 var timestampRe = regexp.MustCompile(`^(\d{4}-\d{2}-\d{2}\s+\d{2}:\d{2}:\d{2}\.\d+(?:[+-]\d{4})?)\s+`)
 
-func parseLine(line string) *PowerEvent {
+// LogEntry is a single unified log entry, parsed from either ndjson or
+// compact `log show` output.
+type LogEntry struct {
+	Timestamp   time.Time
+	Process     string
+	PID         int
+	Subsystem   string
+	Category    string
+	MessageType string
+	Message     string
+}
+
+// ndjsonEntry mirrors the fields of `log show --style ndjson` that we use.
+type ndjsonEntry struct {
+	Timestamp        string `json:"timestamp"`
+	EventType        string `json:"eventType"`
+	MessageType      string `json:"messageType"`
+	EventMessage     string `json:"eventMessage"`
+	Subsystem        string `json:"subsystem"`
+	Category         string `json:"category"`
+	ProcessImagePath string `json:"processImagePath"`
+	ProcessID        int    `json:"processID"`
+}
+
+// parseLogLine parses a single ndjson or compact log line into a LogEntry.
+func parseLogLine(line string) *LogEntry {
+	if strings.HasPrefix(line, "{") {
+		return parseNDJSONLine(line)
+	}
+	return parseCompactLine(line)
+}
+
+func parseNDJSONLine(line string) *LogEntry {
+	var raw ndjsonEntry
+	if err := json.Unmarshal([]byte(line), &raw); err != nil {
+		return nil
+	}
+	if raw.EventType != "" && raw.EventType != "logEvent" {
+		return nil
+	}
+
+	ts, err := parseTimestamp(raw.Timestamp)
+	if err != nil {
+		return nil
+	}
+
+	entry := &LogEntry{
+		Timestamp:   ts,
+		PID:         raw.ProcessID,
+		Subsystem:   raw.Subsystem,
+		Category:    raw.Category,
+		MessageType: raw.MessageType,
+		Message:     raw.EventMessage,
+	}
+	if raw.ProcessImagePath != "" {
+		entry.Process = path.Base(raw.ProcessImagePath)
+	}
+
+	return entry
+}
+
+func parseCompactLine(line string) *LogEntry {
 	m := timestampRe.FindStringSubmatch(line)
 	if len(m) < 2 {
 		return nil
@@ -86,41 +168,75 @@ func parseLine(line string) *PowerEvent {
 		return nil
 	}
 
+	entry := &LogEntry{Timestamp: ts}
 	rest := line[len(m[0]):]
-	lower := strings.ToLower(rest)
+	if p := compactPrefixRe.FindStringSubmatch(rest); len(p) > 5 {
+		entry.MessageType = p[1]
+		entry.Process = strings.TrimSpace(p[2])
+		entry.PID, _ = strconv.Atoi(p[3])
+		entry.Subsystem = p[4]
+		entry.Category = p[5]
+		rest = rest[len(p[0]):]
+	}
+	entry.Message = strings.TrimSpace(rest)
 
-	event := &PowerEvent{
-		Timestamp: ts,
+	return entry
+}
+
+func parseLine(line string) *Event {
+	entry := parseLogLine(line)
+	if entry == nil {
+		return nil
 	}
+	return classifyEntry(entry)
+}
+
+// newEvent returns an untyped event carrying the entry's raw fields.
+func newEvent(entry *LogEntry) *Event {
+	return &Event{
+		Timestamp: entry.Timestamp,
+		Detail:    extractDetail(entry.Message),
+		Process:   entry.Process,
+		PID:       entry.PID,
+		Subsystem: entry.Subsystem,
+		Category:  entry.Category,
+		Message:   entry.Message,
+	}
+}
+
+// classifyEntry applies the built-in powerd classification rules.
+func classifyEntry(entry *LogEntry) *Event {
+	lower := strings.ToLower(entry.Message)
+	event := newEvent(entry)
+	event.Source = SourcePower
 
 	switch {
 	case strings.Contains(lower, "wake reason") || strings.Contains(lower, "waking") ||
 		strings.Contains(lower, "display wake") || strings.Contains(lower, "darkwake") || strings.Contains(lower, "fullwake"):
 		event.Type = EventWake
-		event.Detail = extractDetail(rest)
 	case strings.Contains(lower, "sleep reason") || strings.Contains(lower, "entering sleep") ||
 		strings.Contains(lower, "going to sleep") || strings.Contains(lower, "maintenance sleep") ||
 		strings.Contains(lower, "sleepservice"):
 		event.Type = EventSleep
-		event.Detail = extractDetail(rest)
 	case strings.Contains(lower, "lidopen") || strings.Contains(lower, "lid open"):
 		event.Type = EventLidOpen
-		event.Detail = extractDetail(rest)
 	case strings.Contains(lower, "lidclose") || strings.Contains(lower, "lid close") || strings.Contains(lower, "clamshell"):
 		event.Type = EventLidClose
-		event.Detail = extractDetail(rest)
 	case strings.Contains(lower, "thermal") && (strings.Contains(lower, "throttl") || strings.Contains(lower, "pressure")):
 		event.Type = EventThermal
-		event.Detail = extractDetail(rest)
 	case strings.Contains(lower, "power source") || strings.Contains(lower, "ac power") || strings.Contains(lower, "battery power") ||
 		strings.Contains(lower, "accpowersources"):
 		event.Type = EventPowerSource
-		event.Detail = extractDetail(rest)
 	default:
 		// Skip lines that don't match any known event type.
 		return nil
 	}
 
+	if event.Type == EventWake || event.Type == EventSleep {
+		event.Reason = extractReason(entry.Message)
+		event.ReasonCategory = ClassifyReason(event.Reason, entry.Message)
+	}
+
 	return event
 }
 
@@ -136,44 +252,26 @@ func extractDetail(s string) string {
 	return detail
 }
 
-// DeduplicateEvents collapses consecutive events of the same type within
-// a time window into a single event with a count.
-func DeduplicateEvents(events []PowerEvent, window time.Duration) []PowerEvent {
-	if len(events) == 0 {
-		return nil
-	}
-
-	var result []PowerEvent
-	current := events[0]
-	current.Count = 1
-
-	for i := 1; i < len(events); i++ {
-		e := events[i]
-		if e.Type == current.Type && e.Timestamp.Sub(current.Timestamp) <= window {
-			current.Count++
-		} else {
-			result = append(result, current)
-			current = e
-			current.Count = 1
-		}
-	}
-	result = append(result, current)
-
-	return result
-}
-
 func parseTimestamp(s string) (time.Time, error) {
-	layouts := []string{
+	zoned := []string{
 		"2006-01-02 15:04:05.000000-0700",
 		"2006-01-02 15:04:05.000-0700",
 		"2006-01-02 15:04:05-0700",
+	}
+	for _, layout := range zoned {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+
+	// Compact output has no zone offset; log prints local time.
+	local := []string{
 		"2006-01-02 15:04:05.000000",
 		"2006-01-02 15:04:05.000",
 		"2006-01-02 15:04:05",
 	}
-
-	for _, layout := range layouts {
-		if t, err := time.Parse(layout, s); err == nil {
+	for _, layout := range local {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
 			return t, nil
 		}
 	}
@@ -271,7 +271,7 @@ func TestDeduplicateEvents(t *testing.T) {
 
 	tests := []struct {
 		name       string
-		events     []PowerEvent
+		events     []Event
 		wantLen    int
 		wantCounts []int
 	}{
@@ -282,7 +282,7 @@ func TestDeduplicateEvents(t *testing.T) {
 		},
 		{
 			name: "single event",
-			events: []PowerEvent{
+			events: []Event{
 				{Timestamp: base, Type: EventWake, Detail: "wake"},
 			},
 			wantLen:    1,
@@ -290,7 +290,7 @@ func TestDeduplicateEvents(t *testing.T) {
 		},
 		{
 			name: "3 consecutive same-type within window",
-			events: []PowerEvent{
+			events: []Event{
 				{Timestamp: base, Type: EventPowerSource, Detail: "update 1"},
 				{Timestamp: base.Add(5 * time.Second), Type: EventPowerSource, Detail: "update 2"},
 				{Timestamp: base.Add(10 * time.Second), Type: EventPowerSource, Detail: "update 3"},
@@ -300,7 +300,7 @@ func TestDeduplicateEvents(t *testing.T) {
 		},
 		{
 			name: "2 different types",
-			events: []PowerEvent{
+			events: []Event{
 				{Timestamp: base, Type: EventWake, Detail: "wake"},
 				{Timestamp: base.Add(5 * time.Second), Type: EventSleep, Detail: "sleep"},
 			},
@@ -309,7 +309,7 @@ func TestDeduplicateEvents(t *testing.T) {
 		},
 		{
 			name: "same type but outside window",
-			events: []PowerEvent{
+			events: []Event{
 				{Timestamp: base, Type: EventPowerSource, Detail: "update 1"},
 				{Timestamp: base.Add(60 * time.Second), Type: EventPowerSource, Detail: "update 2"},
 			},
@@ -333,8 +333,8 @@ func TestDeduplicateEvents(t *testing.T) {
 	}
 }
 
-func TestPowerEventJSONRoundTrip(t *testing.T) {
-	event := PowerEvent{
+func TestEventJSONRoundTrip(t *testing.T) {
+	event := Event{
 		Timestamp: time.Date(2025, 1, 15, 10, 30, 0, 0, time.UTC),
 		Type:      EventWake,
 		Detail:    "Wake Reason: EC.LidOpen",
@@ -348,3 +348,102 @@ func TestPowerEventJSONRoundTrip(t *testing.T) {
 		t.Error("expected non-empty Detail")
 	}
 }
+
+func TestParseNDJSONLine(t *testing.T) {
+	line := `{"traceID":1234,"eventMessage":"Wake reason: \"EC.LidOpen\"","eventType":"logEvent","source":null,"formatString":"%s","activityIdentifier":0,"subsystem":"com.apple.powerd","category":"sleepWake","threadID":5678,"processImagePath":"\/System\/Library\/CoreServices\/powerd.bundle\/powerd","timestamp":"2025-01-15 10:30:45.123456-0800","messageType":"Default","processID":323}`
+
+	entry := parseLogLine(line)
+	if entry == nil {
+		t.Fatal("expected non-nil entry")
+	}
+
+	wantTS := time.Date(2025, 1, 15, 18, 30, 45, 123456000, time.UTC)
+	if !entry.Timestamp.Equal(wantTS) {
+		t.Errorf("Timestamp = %v, want %v", entry.Timestamp, wantTS)
+	}
+	if entry.Process != "powerd" {
+		t.Errorf("Process = %q, want %q", entry.Process, "powerd")
+	}
+	if entry.PID != 323 {
+		t.Errorf("PID = %d, want 323", entry.PID)
+	}
+	if entry.Subsystem != "com.apple.powerd" {
+		t.Errorf("Subsystem = %q, want %q", entry.Subsystem, "com.apple.powerd")
+	}
+	if entry.Category != "sleepWake" {
+		t.Errorf("Category = %q, want %q", entry.Category, "sleepWake")
+	}
+	if entry.Message != `Wake reason: "EC.LidOpen"` {
+		t.Errorf("Message = %q", entry.Message)
+	}
+
+	event := parseLine(line)
+	if event == nil {
+		t.Fatal("expected non-nil event")
+	}
+	if event.Type != EventWake {
+		t.Errorf("Type = %q, want %q", event.Type, EventWake)
+	}
+	if event.Process != "powerd" || event.Category != "sleepWake" {
+		t.Errorf("expected raw fields on event, got %+v", event)
+	}
+}
+
+func TestParseNDJSONLineSkipsNonLogEvents(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+	}{
+		{name: "activity event", line: `{"eventType":"activityCreateEvent","eventMessage":"Wake reason","timestamp":"2025-01-15 10:30:45.123456-0800"}`},
+		{name: "bad timestamp", line: `{"eventType":"logEvent","eventMessage":"Wake reason","timestamp":"yesterday"}`},
+		{name: "invalid JSON", line: `{"eventType":`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if entry := parseLogLine(tt.line); entry != nil {
+				t.Errorf("expected nil, got %+v", entry)
+			}
+		})
+	}
+}
+
+func TestParseCompactLineFields(t *testing.T) {
+	line := "2025-01-15 10:30:45.123 Df powerd[323:d9137a] [com.apple.powerd:battery] Received power source update"
+
+	entry := parseLogLine(line)
+	if entry == nil {
+		t.Fatal("expected non-nil entry")
+	}
+	if entry.MessageType != "Df" {
+		t.Errorf("MessageType = %q, want %q", entry.MessageType, "Df")
+	}
+	if entry.Process != "powerd" || entry.PID != 323 {
+		t.Errorf("Process/PID = %q/%d, want powerd/323", entry.Process, entry.PID)
+	}
+	if entry.Subsystem != "com.apple.powerd" || entry.Category != "battery" {
+		t.Errorf("Subsystem/Category = %q/%q", entry.Subsystem, entry.Category)
+	}
+	if entry.Message != "Received power source update" {
+		t.Errorf("Message = %q", entry.Message)
+	}
+	// Compact timestamps carry no zone and are read as local time.
+	if entry.Timestamp.Location() != time.Local {
+		t.Errorf("Timestamp location = %v, want Local", entry.Timestamp.Location())
+	}
+}
+
+func TestParseLogOutputNDJSON(t *testing.T) {
+	input := `{"eventType":"logEvent","eventMessage":"Wake reason: EC.LidOpen","subsystem":"com.apple.powerd","processImagePath":"/usr/libexec/powerd","timestamp":"2025-01-15 08:00:00.123000-0800","processID":323}
+{"eventType":"logEvent","eventMessage":"Some unrelated log entry","subsystem":"com.apple.powerd","timestamp":"2025-01-15 08:00:01.000000-0800"}
+{"eventType":"logEvent","eventMessage":"Entering sleep reason: Clamshell Sleep","subsystem":"com.apple.powerd","timestamp":"2025-01-15 18:00:00.012000-0800"}
+`
+
+	events := parseLogOutput(input)
+	if len(events) != 2 {
+		t.Fatalf("expected 2 events, got %d", len(events))
+	}
+	if events[0].Type != EventWake || events[1].Type != EventSleep {
+		t.Errorf("types = %q, %q; want wake, sleep", events[0].Type, events[1].Type)
+	}
+}
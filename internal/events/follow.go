@@ -0,0 +1,96 @@
+package events
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+const (
+	// followMinBackoff and followMaxBackoff bound the delay before restarting
+	// `log stream` after it exits unexpectedly.
+	followMinBackoff = time.Second
+	followMaxBackoff = 30 * time.Second
+)
+
+// Follow streams events from `log stream` as they happen, classifying them
+// with c and calling fn for each one until ctx is cancelled. If the log
+// process dies it is restarted with exponential backoff; onRestart, if
+// non-nil, is told why and how long until the next attempt.
+func Follow(ctx context.Context, c *Classifier, fn func(Event), onRestart func(err error, delay time.Duration)) error {
+	backoff := followMinBackoff
+	for {
+		started := time.Now()
+		err := streamOnce(ctx, c, fn)
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		// Reset the backoff if the stream ran for a while before dying.
+		if time.Since(started) > followMaxBackoff {
+			backoff = followMinBackoff
+		}
+
+		if err == nil {
+			err = fmt.Errorf("log stream exited")
+		}
+		if onRestart != nil {
+			onRestart(err, backoff)
+		}
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil
+		case <-timer.C:
+		}
+
+		backoff *= 2
+		if backoff > followMaxBackoff {
+			backoff = followMaxBackoff
+		}
+	}
+}
+
+func streamOnce(ctx context.Context, c *Classifier, fn func(Event)) error {
+	cmd := exec.CommandContext(ctx, "log", "stream",
+		"--predicate", c.Predicate(),
+		"--style", "ndjson",
+	)
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return fmt.Errorf("failed to open log stream: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start log stream: %w", err)
+	}
+
+	scanErr := scanEvents(stdout, c, fn)
+	waitErr := cmd.Wait()
+	if scanErr != nil {
+		return scanErr
+	}
+	return waitErr
+}
+
+// scanEvents reads log lines from r and calls fn for each event c classifies.
+func scanEvents(r io.Reader, c *Classifier, fn func(Event)) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		entry := parseLogLine(strings.TrimSpace(scanner.Text()))
+		if entry == nil {
+			continue
+		}
+		if event := c.Classify(entry); event != nil {
+			fn(*event)
+		}
+	}
+	return scanner.Err()
+}
@@ -0,0 +1,32 @@
+package events
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestScanEvents(t *testing.T) {
+	input := `Filtering the log data using "subsystem == "com.apple.powerd""
+Timestamp               Ty Process[PID:TID]
+2025-01-15 08:00:00.123 Df powerd[323:1a2b] [com.apple.powerd:assertions] Wake Reason: EC.LidOpen
+2025-01-15 08:00:05.000 Df powerd[323:1a2b] [com.apple.powerd:assertions] Some unrelated log entry
+  2025-01-15 08:00:09.456 Df powerd[323:d9137a] [com.apple.powerd:battery] Received power source(psid:6829) update from pid 669: <private>
+`
+
+	var got []Event
+	if err := scanEvents(strings.NewReader(input), nil, func(e Event) {
+		got = append(got, e)
+	}); err != nil {
+		t.Fatalf("scanEvents returned error: %v", err)
+	}
+
+	if len(got) != 2 {
+		t.Fatalf("expected 2 events, got %d: %+v", len(got), got)
+	}
+	if got[0].Type != EventWake {
+		t.Errorf("first event Type = %q, want %q", got[0].Type, EventWake)
+	}
+	if got[1].Type != EventPowerSource {
+		t.Errorf("second event Type = %q, want %q", got[1].Type, EventPowerSource)
+	}
+}
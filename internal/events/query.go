@@ -0,0 +1,189 @@
+package events
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/lu-zhengda/macctl/internal/power"
+)
+
+// DefaultPageSize is the window queried per `log show` call. Large windows
+// are split into pages so no single call has to buffer days of log output.
+const DefaultPageSize = 24 * time.Hour
+
+// logTimeLayout is the format `log show --start/--end` accepts, in local time.
+const logTimeLayout = "2006-01-02 15:04:05"
+
+// Query selects a time window of the system log. Classifier, if set, adds
+// user rules (and the subsystems they watch) to the built-in classifier.
+type Query struct {
+	Since      time.Time
+	Until      time.Time
+	PageSize   time.Duration
+	Classifier *Classifier
+}
+
+// LastQuery returns a Query covering the given duration up to now.
+func LastQuery(d time.Duration) Query {
+	now := time.Now()
+	return Query{Since: now.Add(-d), Until: now}
+}
+
+// QueryEvents streams power events in [q.Since, q.Until] page by page,
+// calling fn for each event in timestamp order.
+func QueryEvents(q Query, fn func(Event)) error {
+	if q.Until.IsZero() {
+		q.Until = time.Now()
+	}
+	if q.Since.IsZero() {
+		q.Since = q.Until.Add(-24 * time.Hour)
+	}
+	if !q.Since.Before(q.Until) {
+		return fmt.Errorf("start time %s is not before end time %s",
+			q.Since.Local().Format(logTimeLayout), q.Until.Local().Format(logTimeLayout))
+	}
+	if q.PageSize <= 0 {
+		q.PageSize = DefaultPageSize
+	}
+
+	for start := q.Since; start.Before(q.Until); start = start.Add(q.PageSize) {
+		end := start.Add(q.PageSize)
+		last := !end.Before(q.Until)
+		if last {
+			end = q.Until
+		}
+
+		// log show takes whole seconds, so pages overlap in the second at
+		// each boundary. Each page keeps only events at or after its start
+		// and, except for the last, strictly before its end.
+		pageStart := start
+		err := runPage(q.Classifier, start, end, func(e Event) {
+			if e.Timestamp.Before(pageStart) || (!last && !e.Timestamp.Before(end)) {
+				return
+			}
+			fn(e)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// CollectEvents returns all power events matching q.
+func CollectEvents(q Query) ([]Event, error) {
+	var events []Event
+	err := QueryEvents(q, func(e Event) {
+		events = append(events, e)
+	})
+	return events, err
+}
+
+// runPage queries one page of the system log; tests replace it.
+var runPage = queryPage
+
+func queryPage(c *Classifier, start, end time.Time, fn func(Event)) error {
+	emitted := 0
+	err := logShowPage(c, "ndjson", start, end, func(e Event) {
+		emitted++
+		fn(e)
+	})
+	if err != nil && emitted == 0 {
+		// Older macOS releases don't support ndjson; fall back to compact.
+		err = logShowPage(c, "compact", start, end, fn)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to query system log: %w", err)
+	}
+	return nil
+}
+
+func logShowPage(c *Classifier, style string, start, end time.Time, fn func(Event)) error {
+	cmd := exec.Command("log", "show",
+		"--predicate", c.Predicate(),
+		"--style", style,
+		"--start", start.Local().Format(logTimeLayout),
+		"--end", end.Local().Format(logTimeLayout),
+	)
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	scanErr := scanEvents(stdout, c, fn)
+	waitErr := cmd.Wait()
+	if scanErr != nil {
+		return scanErr
+	}
+	return waitErr
+}
+
+// ParseTime parses an absolute or relative time for --since/--until.
+// Accepted forms are RFC3339 ("2025-01-15T22:00:00-08:00"), local dates and
+// times ("2025-01-15", "2025-01-15 22:00"), "now", "today" or "yesterday"
+// with an optional time ("yesterday 22:00"), and durations ago ("6h ago").
+func ParseTime(s string, now time.Time) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	lower := strings.ToLower(s)
+
+	if lower == "now" {
+		return now, nil
+	}
+
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+
+	for _, layout := range []string{
+		"2006-01-02 15:04:05",
+		"2006-01-02T15:04:05",
+		"2006-01-02 15:04",
+		"2006-01-02T15:04",
+		"2006-01-02",
+	} {
+		if t, err := time.ParseInLocation(layout, s, now.Location()); err == nil {
+			return t, nil
+		}
+	}
+
+	if rest, ok := strings.CutSuffix(lower, " ago"); ok {
+		d, err := power.ParseDuration(strings.TrimSpace(rest))
+		if err != nil {
+			return time.Time{}, err
+		}
+		return now.Add(-d), nil
+	}
+
+	fields := strings.Fields(lower)
+	if len(fields) == 0 || len(fields) > 2 {
+		return time.Time{}, fmt.Errorf("unrecognized time: %q", s)
+	}
+
+	day := now
+	switch fields[0] {
+	case "today":
+	case "yesterday":
+		day = now.AddDate(0, 0, -1)
+	default:
+		return time.Time{}, fmt.Errorf("unrecognized time: %q", s)
+	}
+	y, m, d := day.Date()
+
+	if len(fields) == 1 {
+		return time.Date(y, m, d, 0, 0, 0, 0, now.Location()), nil
+	}
+
+	for _, layout := range []string{"15:04", "15:04:05"} {
+		if t, err := time.Parse(layout, fields[1]); err == nil {
+			return time.Date(y, m, d, t.Hour(), t.Minute(), t.Second(), 0, now.Location()), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unrecognized time of day in %q", s)
+}
@@ -0,0 +1,104 @@
+package events
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseTime(t *testing.T) {
+	loc := time.FixedZone("PST", -8*3600)
+	now := time.Date(2025, 1, 15, 10, 30, 0, 0, loc)
+
+	tests := []struct {
+		name    string
+		input   string
+		want    time.Time
+		wantErr bool
+	}{
+		{name: "now", input: "now", want: now},
+		{name: "rfc3339", input: "2025-01-14T22:00:00Z", want: time.Date(2025, 1, 14, 22, 0, 0, 0, time.UTC)},
+		{name: "date", input: "2025-01-10", want: time.Date(2025, 1, 10, 0, 0, 0, 0, loc)},
+		{name: "date and time", input: "2025-01-10 08:15", want: time.Date(2025, 1, 10, 8, 15, 0, 0, loc)},
+		{name: "date and time with seconds", input: "2025-01-10T08:15:30", want: time.Date(2025, 1, 10, 8, 15, 30, 0, loc)},
+		{name: "today", input: "today", want: time.Date(2025, 1, 15, 0, 0, 0, 0, loc)},
+		{name: "yesterday with time", input: "yesterday 22:00", want: time.Date(2025, 1, 14, 22, 0, 0, 0, loc)},
+		{name: "today with seconds", input: "Today 07:05:09", want: time.Date(2025, 1, 15, 7, 5, 9, 0, loc)},
+		{name: "duration ago", input: "6h ago", want: now.Add(-6 * time.Hour)},
+		{name: "days ago", input: "2d ago", want: now.Add(-48 * time.Hour)},
+		{name: "bad duration ago", input: "6x ago", wantErr: true},
+		{name: "bad time of day", input: "yesterday 25:00", wantErr: true},
+		{name: "unknown word", input: "tomorrow", wantErr: true},
+		{name: "empty", input: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseTime(tt.input, now)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("ParseTime(%q) expected error, got %v", tt.input, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseTime(%q) unexpected error: %v", tt.input, err)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("ParseTime(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQueryEventsRejectsInvertedRange(t *testing.T) {
+	now := time.Now()
+	err := QueryEvents(Query{Since: now, Until: now.Add(-time.Hour)}, func(Event) {})
+	if err == nil {
+		t.Error("expected error for start after end")
+	}
+}
+
+func TestQueryEventsPagesSubSecondBoundaries(t *testing.T) {
+	base := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	since := base.Add(250 * time.Millisecond)
+	all := []Event{
+		{Timestamp: base.Add(100 * time.Millisecond), Message: "before since"},
+		{Timestamp: base.Add(500 * time.Millisecond), Message: "a"},
+		{Timestamp: since.Add(time.Hour - 100*time.Millisecond), Message: "end of page 1"},
+		{Timestamp: since.Add(time.Hour + 300*time.Millisecond), Message: "start of page 2"},
+		{Timestamp: since.Add(2*time.Hour - 50*time.Millisecond), Message: "last"},
+	}
+
+	// Fake log show: whole-second bounds, end second included.
+	orig := runPage
+	defer func() { runPage = orig }()
+	runPage = func(_ *Classifier, start, end time.Time, fn func(Event)) error {
+		from, to := start.Truncate(time.Second), end.Truncate(time.Second).Add(time.Second)
+		for _, e := range all {
+			if !e.Timestamp.Before(from) && e.Timestamp.Before(to) {
+				fn(e)
+			}
+		}
+		return nil
+	}
+
+	got, err := CollectEvents(Query{Since: since, Until: since.Add(2 * time.Hour), PageSize: time.Hour})
+	if err != nil {
+		t.Fatalf("CollectEvents: %v", err)
+	}
+
+	var msgs []string
+	for _, e := range got {
+		msgs = append(msgs, e.Message)
+	}
+	want := []string{"a", "end of page 1", "start of page 2", "last"}
+	if len(msgs) != len(want) {
+		t.Fatalf("events = %v, want %v", msgs, want)
+	}
+	for i := range want {
+		if msgs[i] != want[i] {
+			t.Errorf("events = %v, want %v", msgs, want)
+			break
+		}
+	}
+}
@@ -0,0 +1,185 @@
+package events
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Reason category constants for normalized wake and sleep reasons.
+const (
+	ReasonLid         = "lid"
+	ReasonPowerButton = "power_button"
+	ReasonRTC         = "rtc_maintenance"
+	ReasonNetwork     = "network"
+	ReasonUSBHID      = "usb_hid"
+	ReasonDarkWake    = "darkwake"
+	ReasonIdle        = "idle"
+	ReasonSoftware    = "software"
+	ReasonLowBattery  = "low_battery"
+	ReasonThermal     = "thermal"
+	ReasonOther       = "other"
+)
+
+// reasonPatterns extract the raw reason from powerd messages, in order:
+//
+//	Wake reason: "EC.LidOpen"
+//	Entering Sleep state due to 'Clamshell Sleep'
+//	DarkWake from Deep Idle [CDN] : due to SMC.OutboxNotEmpty/ Using BATT
+//	Entering sleep reason: Clamshell Sleep
+var reasonPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)reason:?\s*"([^"]+)"`),
+	regexp.MustCompile(`(?i)due to\s+'([^']+)'`),
+	regexp.MustCompile(`(?i)due to\s+(.+?)(?:\s+Using\b|$)`),
+	regexp.MustCompile(`(?i)reason:?\s*([^,;]+)`),
+}
+
+// reasonRules map substrings of a lowercased reason to a category. The first
+// matching rule wins.
+var reasonRules = []struct {
+	category string
+	needles  []string
+}{
+	{ReasonLid, []string{"lid", "clamshell"}},
+	{ReasonPowerButton, []string{"pwrbtn", "power button", "powerbutton"}},
+	{ReasonRTC, []string{"rtc", "maintenance", "alarm", "scheduled"}},
+	{ReasonNetwork, []string{"wol", "wake on lan", "magic packet", "arpt", "enet", "network", "wifi", "wlan"}},
+	{ReasonUSBHID, []string{"usb", "xhc", "hid", "keyboard", "mouse", "trackpad", "useractivity", "user activity"}},
+	{ReasonDarkWake, []string{"push", "apns", "tcpka", "tcpkeepalive", "tcp keepalive"}},
+	{ReasonLowBattery, []string{"low power", "low battery", "lowbattery"}},
+	{ReasonThermal, []string{"thermal"}},
+	{ReasonIdle, []string{"idle"}},
+	{ReasonSoftware, []string{"software"}},
+}
+
+// extractReason returns the raw wake or sleep reason in a powerd message.
+func extractReason(message string) string {
+	for _, re := range reasonPatterns {
+		if m := re.FindStringSubmatch(message); len(m) > 1 {
+			reason := strings.Trim(strings.TrimSpace(m[1]), `"'/`)
+			if reason != "" {
+				return reason
+			}
+		}
+	}
+	return ""
+}
+
+// ClassifyReason normalizes a raw wake or sleep reason into a category.
+// message is the full log message; it's used when the reason is empty and to
+// recognize DarkWakes whose reason doesn't say why.
+func ClassifyReason(reason, message string) string {
+	darkWake := strings.Contains(strings.ToLower(message), "darkwake")
+
+	text := strings.ToLower(reason)
+	if text == "" {
+		if darkWake {
+			return ReasonDarkWake
+		}
+		text = strings.ToLower(message)
+	}
+
+	for _, rule := range reasonRules {
+		for _, needle := range rule.needles {
+			if strings.Contains(text, needle) {
+				return rule.category
+			}
+		}
+	}
+
+	if darkWake {
+		return ReasonDarkWake
+	}
+	return ReasonOther
+}
+
+// ReasonCount holds the number of wakes for a reason or category.
+type ReasonCount struct {
+	Reason string `json:"reason"`
+	Count  int    `json:"count"`
+}
+
+// NightWakes holds the wakes during a single night, from noon to noon.
+type NightWakes struct {
+	Night      string         `json:"night"`
+	Total      int            `json:"total"`
+	Categories map[string]int `json:"categories"`
+}
+
+// WakeReport summarizes wake events by normalized category, raw reason, and night.
+type WakeReport struct {
+	Total      int           `json:"total"`
+	ByCategory []ReasonCount `json:"by_category"`
+	ByReason   []ReasonCount `json:"by_reason"`
+	Nights     []NightWakes  `json:"nights"`
+}
+
+// SummarizeWakes counts wake events per reason category, per raw reason, and
+// per night. A night is keyed by the date it starts on, so wakes from noon
+// until noon the next day are grouped together. A deduplicated event counts
+// as the Count wakes it stands for.
+func SummarizeWakes(events []Event) *WakeReport {
+	report := &WakeReport{}
+	categories := make(map[string]int)
+	reasons := make(map[string]int)
+	nights := make(map[string]*NightWakes)
+
+	for _, e := range events {
+		if e.Type != EventWake {
+			continue
+		}
+
+		category := e.ReasonCategory
+		if category == "" {
+			category = ClassifyReason(e.Reason, e.Message)
+		}
+		reason := e.Reason
+		if reason == "" {
+			reason = "(unknown)"
+		}
+
+		n := max(e.Count, 1)
+		report.Total += n
+		categories[category] += n
+		reasons[reason] += n
+
+		key := nightOf(e.Timestamp)
+		night, ok := nights[key]
+		if !ok {
+			night = &NightWakes{Night: key, Categories: make(map[string]int)}
+			nights[key] = night
+		}
+		night.Total += n
+		night.Categories[category] += n
+	}
+
+	report.ByCategory = sortedCounts(categories)
+	report.ByReason = sortedCounts(reasons)
+	for _, n := range nights {
+		report.Nights = append(report.Nights, *n)
+	}
+	sort.Slice(report.Nights, func(i, j int) bool {
+		return report.Nights[i].Night < report.Nights[j].Night
+	})
+
+	return report
+}
+
+func nightOf(ts time.Time) string {
+	return ts.Local().Add(-12 * time.Hour).Format("2006-01-02")
+}
+
+func sortedCounts(m map[string]int) []ReasonCount {
+	counts := make([]ReasonCount, 0, len(m))
+	for k, v := range m {
+		counts = append(counts, ReasonCount{Reason: k, Count: v})
+	}
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].Count != counts[j].Count {
+			return counts[i].Count > counts[j].Count
+		}
+		return counts[i].Reason < counts[j].Reason
+	})
+	return counts
+}
@@ -0,0 +1,150 @@
+package events
+
+import (
+	"testing"
+	"time"
+)
+
+func TestExtractReason(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		want    string
+	}{
+		{name: "quoted wake reason", message: `Wake reason: "EC.LidOpen"`, want: "EC.LidOpen"},
+		{name: "unquoted wake reason", message: "Wake Reason: EC.LidOpen", want: "EC.LidOpen"},
+		{name: "sleep due to quoted", message: "Entering Sleep state due to 'Clamshell Sleep':TCPKeepAlive=active Using Batt (Charge:76%)", want: "Clamshell Sleep"},
+		{name: "wake due to", message: "Wake from Deep Idle [CDNVA] : due to EC.LidOpen/Lid Open Using BATT (Charge:71%)", want: "EC.LidOpen/Lid Open"},
+		{name: "darkwake due to with trailing slash", message: "DarkWake from Deep Idle [CDN] : due to SMC.OutboxNotEmpty/ Using BATT (Charge:75%)", want: "SMC.OutboxNotEmpty"},
+		{name: "entering sleep reason", message: "Entering sleep reason: Clamshell Sleep", want: "Clamshell Sleep"},
+		{name: "fullwake reason", message: "FullWake reason: UserActivity", want: "UserActivity"},
+		{name: "no reason", message: "DarkWake from Deep Idle", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := extractReason(tt.message); got != tt.want {
+				t.Errorf("extractReason(%q) = %q, want %q", tt.message, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClassifyReason(t *testing.T) {
+	tests := []struct {
+		name    string
+		reason  string
+		message string
+		want    string
+	}{
+		{name: "lid open", reason: "EC.LidOpen", want: ReasonLid},
+		{name: "clamshell sleep", reason: "Clamshell Sleep", want: ReasonLid},
+		{name: "power button", reason: "EC.PowerButton", want: ReasonPowerButton},
+		{name: "rtc maintenance", reason: "NUB.SPMI0.SW3 RTC/Maintenance", want: ReasonRTC},
+		{name: "wifi", reason: "SMC.OutboxNotEmpty smc.70070000 wifibt", want: ReasonNetwork},
+		{name: "wake on lan", reason: "ARPT/WOL", want: ReasonNetwork},
+		{name: "usb", reason: "XHC1", want: ReasonUSBHID},
+		{name: "user activity", reason: "UserActivity", want: ReasonUSBHID},
+		{name: "tcp keepalive", reason: "TCPKA", want: ReasonDarkWake},
+		{name: "idle sleep", reason: "Idle Sleep", want: ReasonIdle},
+		{name: "software sleep", reason: "Software Sleep", want: ReasonSoftware},
+		{name: "low power sleep", reason: "Low Power Sleep", want: ReasonLowBattery},
+		{name: "unknown darkwake", reason: "SMC.OutboxNotEmpty", message: "DarkWake from Deep Idle [CDN] : due to SMC.OutboxNotEmpty/", want: ReasonDarkWake},
+		{name: "darkwake without reason", message: "DarkWake from Deep Idle", want: ReasonDarkWake},
+		{name: "unknown", reason: "XYZ.Something", message: "Wake reason: XYZ.Something", want: ReasonOther},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ClassifyReason(tt.reason, tt.message); got != tt.want {
+				t.Errorf("ClassifyReason(%q, %q) = %q, want %q", tt.reason, tt.message, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseLineSetsReason(t *testing.T) {
+	event := parseLine(`2025-01-15 10:30:45.123 Df powerd[323:1a2b] [com.apple.powerd:sleepWake] Wake reason: "EC.LidOpen"`)
+	if event == nil {
+		t.Fatal("expected non-nil event")
+	}
+	if event.Reason != "EC.LidOpen" {
+		t.Errorf("Reason = %q, want %q", event.Reason, "EC.LidOpen")
+	}
+	if event.ReasonCategory != ReasonLid {
+		t.Errorf("ReasonCategory = %q, want %q", event.ReasonCategory, ReasonLid)
+	}
+
+	event = parseLine("2025-01-15 10:30:45.123 Df powerd[323:1a2b] [com.apple.powerd:battery] Received power source(psid:6829) update")
+	if event == nil {
+		t.Fatal("expected non-nil event")
+	}
+	if event.Reason != "" || event.ReasonCategory != "" {
+		t.Errorf("expected no reason on power source event, got %q/%q", event.Reason, event.ReasonCategory)
+	}
+}
+
+func TestSummarizeWakes(t *testing.T) {
+	// Use local times so night boundaries are independent of the test machine's zone.
+	night1 := time.Date(2025, 1, 14, 23, 0, 0, 0, time.Local)
+	night2 := time.Date(2025, 1, 15, 22, 0, 0, 0, time.Local)
+
+	evts := []Event{
+		{Timestamp: night1, Type: EventWake, Reason: "SMC.OutboxNotEmpty", ReasonCategory: ReasonDarkWake},
+		{Timestamp: night1.Add(3 * time.Hour), Type: EventWake, Reason: "RTC/Maintenance", ReasonCategory: ReasonRTC},
+		// 07:00 the next morning still belongs to the night of the 14th.
+		{Timestamp: night1.Add(8 * time.Hour), Type: EventWake, Reason: "EC.LidOpen", ReasonCategory: ReasonLid},
+		{Timestamp: night1.Add(8 * time.Hour), Type: EventSleep, Reason: "Idle Sleep", ReasonCategory: ReasonIdle},
+		{Timestamp: night2, Type: EventWake, Reason: "SMC.OutboxNotEmpty", ReasonCategory: ReasonDarkWake},
+		// Category is derived when the event has none.
+		{Timestamp: night2.Add(time.Hour), Type: EventWake, Message: "DarkWake from Deep Idle"},
+	}
+
+	report := SummarizeWakes(evts)
+
+	if report.Total != 5 {
+		t.Errorf("Total = %d, want 5", report.Total)
+	}
+	if len(report.ByCategory) == 0 || report.ByCategory[0].Reason != ReasonDarkWake || report.ByCategory[0].Count != 3 {
+		t.Errorf("ByCategory[0] = %+v, want darkwake x3", report.ByCategory)
+	}
+	if len(report.Nights) != 2 {
+		t.Fatalf("expected 2 nights, got %d: %+v", len(report.Nights), report.Nights)
+	}
+	if report.Nights[0].Night != "2025-01-14" || report.Nights[0].Total != 3 {
+		t.Errorf("Nights[0] = %+v, want 2025-01-14 with 3 wakes", report.Nights[0])
+	}
+	if report.Nights[1].Night != "2025-01-15" || report.Nights[1].Total != 2 {
+		t.Errorf("Nights[1] = %+v, want 2025-01-15 with 2 wakes", report.Nights[1])
+	}
+	if report.Nights[0].Categories[ReasonLid] != 1 {
+		t.Errorf("Nights[0] lid count = %d, want 1", report.Nights[0].Categories[ReasonLid])
+	}
+}
+
+func TestSummarizeWakesBackToBack(t *testing.T) {
+	base := time.Date(2025, 1, 14, 23, 0, 0, 0, time.Local)
+	evts := []Event{
+		{Timestamp: base, Source: SourcePower, Type: EventWake, Reason: "EC.LidOpen", ReasonCategory: ReasonLid},
+		{Timestamp: base.Add(5 * time.Second), Source: SourcePower, Type: EventWake, Reason: "RTC/Maintenance", ReasonCategory: ReasonRTC},
+		{Timestamp: base.Add(10 * time.Second), Source: SourcePower, Type: EventWake, Reason: "RTC/Maintenance", ReasonCategory: ReasonRTC},
+	}
+
+	report := SummarizeWakes(evts)
+	if report.Total != 3 {
+		t.Errorf("Total = %d, want 3", report.Total)
+	}
+	byReason := map[string]int{}
+	for _, r := range report.ByReason {
+		byReason[r.Reason] = r.Count
+	}
+	if byReason["EC.LidOpen"] != 1 || byReason["RTC/Maintenance"] != 2 {
+		t.Errorf("ByReason = %+v, want lid=1 rtc=2", report.ByReason)
+	}
+
+	// Events deduplicated by type and reason still count every wake.
+	deduped := Deduplicate(evts, DedupOptions{Window: DefaultDedupWindow, By: DedupByReason})
+	if got := SummarizeWakes(deduped); got.Total != 3 || got.Nights[0].Categories[ReasonRTC] != 2 {
+		t.Errorf("deduplicated report = %+v, want 3 wakes with rtc=2", got)
+	}
+}
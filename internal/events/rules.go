@@ -0,0 +1,286 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const rulesFileName = "event-rules.json"
+
+// Rule is a user-defined event classification rule. Log entries whose
+// subsystem and process match (when set) and whose message matches the
+// regular expression become events of type Name. Named capture groups in
+// Match are extracted into the event's fields.
+type Rule struct {
+	Name      string   `json:"name"`
+	Subsystem string   `json:"subsystem,omitempty"`
+	Process   string   `json:"process,omitempty"`
+	Match     string   `json:"match"`
+	Fields    []string `json:"fields,omitempty"`
+}
+
+type rulesFile struct {
+	Rules []Rule `json:"rules"`
+}
+
+type compiledRule struct {
+	Rule
+	re *regexp.Regexp
+}
+
+// Classifier turns log entries into events using user rules first and the
+// built-in classifiers of the selected sources second. A nil Classifier uses
+// only the default sources.
+type Classifier struct {
+	rules   []compiledRule
+	sources []*source
+}
+
+// RulesPath returns the path to the user's event rules file.
+func RulesPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(home, ".config", "macctl", rulesFileName), nil
+}
+
+// LoadRules reads user rules from the rules file. A missing file means no rules.
+func LoadRules() ([]Rule, error) {
+	path, err := RulesPath()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read event rules file: %w", err)
+	}
+
+	var f rulesFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("failed to parse event rules file: %w", err)
+	}
+	return f.Rules, nil
+}
+
+// NewClassifier validates and compiles rules into a Classifier that also
+// classifies entries from the named sources (DefaultSources if empty).
+func NewClassifier(rules []Rule, sourceNames []string) (*Classifier, error) {
+	if len(sourceNames) == 0 {
+		sourceNames = DefaultSources
+	}
+
+	c := &Classifier{}
+	for _, name := range sourceNames {
+		s := lookupSource(name)
+		if s == nil {
+			return nil, fmt.Errorf("unknown event source %q", name)
+		}
+		c.sources = append(c.sources, s)
+	}
+
+	for i, r := range rules {
+		if r.Name == "" {
+			return nil, fmt.Errorf("event rule %d: name is required", i+1)
+		}
+		if r.Match == "" {
+			return nil, fmt.Errorf("event rule %q: match is required", r.Name)
+		}
+		re, err := regexp.Compile(r.Match)
+		if err != nil {
+			return nil, fmt.Errorf("event rule %q: invalid match: %w", r.Name, err)
+		}
+		c.rules = append(c.rules, compiledRule{Rule: r, re: re})
+	}
+	return c, nil
+}
+
+// LoadClassifier builds a Classifier from the user's rules file and the
+// named sources.
+func LoadClassifier(sourceNames []string) (*Classifier, error) {
+	rules, err := LoadRules()
+	if err != nil {
+		return nil, err
+	}
+	return NewClassifier(rules, sourceNames)
+}
+
+// Rules returns the classifier's user rules.
+func (c *Classifier) Rules() []Rule {
+	if c == nil {
+		return nil
+	}
+	rules := make([]Rule, len(c.rules))
+	for i, r := range c.rules {
+		rules[i] = r.Rule
+	}
+	return rules
+}
+
+// Sources returns the names of the classifier's sources.
+func (c *Classifier) Sources() []string {
+	var names []string
+	for _, s := range c.sourceList() {
+		names = append(names, s.name)
+	}
+	return names
+}
+
+func (c *Classifier) sourceList() []*source {
+	if c == nil || len(c.sources) == 0 {
+		var list []*source
+		for _, name := range DefaultSources {
+			list = append(list, lookupSource(name))
+		}
+		return list
+	}
+	return c.sources
+}
+
+// Types returns all event types the classifier can produce.
+func (c *Classifier) Types() []string {
+	var types []string
+	for _, s := range c.sourceList() {
+		types = append(types, s.types...)
+	}
+	for _, r := range c.Rules() {
+		types = append(types, r.Name)
+	}
+	return types
+}
+
+// HasType reports whether the classifier can produce events of type t.
+func (c *Classifier) HasType(t string) bool {
+	for _, known := range c.Types() {
+		if known == t {
+			return true
+		}
+	}
+	return false
+}
+
+// Selects reports whether e is an event the classifier could have produced:
+// one of its rules' types, or a type from one of its sources.
+func (c *Classifier) Selects(e Event) bool {
+	if e.Source == SourceCustom {
+		for _, r := range c.Rules() {
+			if r.Name == e.Type {
+				return true
+			}
+		}
+		return false
+	}
+
+	for _, s := range c.sourceList() {
+		if s.name == e.Source {
+			return true
+		}
+	}
+	return false
+}
+
+// Predicate returns the `log` predicate selecting entries the classifier
+// can match: the selected sources plus every subsystem and process named by
+// a rule.
+func (c *Classifier) Predicate() string {
+	var clauses []string
+	seen := make(map[string]bool)
+	for _, s := range c.sourceList() {
+		for _, clause := range s.clauses() {
+			if !seen[clause] {
+				seen[clause] = true
+				clauses = append(clauses, clause)
+			}
+		}
+	}
+	builtin := len(clauses)
+
+	for _, r := range c.Rules() {
+		var clause string
+		switch {
+		case r.Subsystem != "":
+			clause = "subsystem == " + strconv.Quote(r.Subsystem)
+		case r.Process != "":
+			clause = "process == " + strconv.Quote(r.Process)
+		default:
+			continue
+		}
+		if !seen[clause] {
+			seen[clause] = true
+			clauses = append(clauses, clause)
+		}
+	}
+
+	sort.Strings(clauses[builtin:])
+	return strings.Join(clauses, " OR ")
+}
+
+// Classify returns the event for a log entry, or nil if no rule matches.
+func (c *Classifier) Classify(entry *LogEntry) *Event {
+	if c != nil {
+		for _, r := range c.rules {
+			if event := r.apply(entry); event != nil {
+				return event
+			}
+		}
+	}
+
+	for _, s := range c.sourceList() {
+		if event := s.apply(entry); event != nil {
+			return event
+		}
+	}
+	return nil
+}
+
+func (r compiledRule) apply(entry *LogEntry) *Event {
+	if r.Subsystem != "" && r.Subsystem != entry.Subsystem {
+		return nil
+	}
+	if r.Process != "" && r.Process != entry.Process {
+		return nil
+	}
+
+	m := r.re.FindStringSubmatch(entry.Message)
+	if m == nil {
+		return nil
+	}
+
+	event := newEvent(entry)
+	event.Source = SourceCustom
+	event.Type = r.Name
+
+	for i, name := range r.re.SubexpNames() {
+		if name == "" || i >= len(m) || !r.wantsField(name) {
+			continue
+		}
+		if event.Fields == nil {
+			event.Fields = make(map[string]string)
+		}
+		event.Fields[name] = m[i]
+	}
+
+	return event
+}
+
+func (r compiledRule) wantsField(name string) bool {
+	if len(r.Fields) == 0 {
+		return true
+	}
+	for _, f := range r.Fields {
+		if f == name {
+			return true
+		}
+	}
+	return false
+}
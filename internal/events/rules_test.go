@@ -0,0 +1,149 @@
+package events
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNewClassifierValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		rules   []Rule
+		wantErr string
+	}{
+		{name: "valid", rules: []Rule{{Name: "bt", Match: "disconnected"}}},
+		{name: "missing name", rules: []Rule{{Match: "x"}}, wantErr: "name is required"},
+		{name: "missing match", rules: []Rule{{Name: "bt"}}, wantErr: "match is required"},
+		{name: "bad regex", rules: []Rule{{Name: "bt", Match: "("}}, wantErr: "invalid match"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewClassifier(tt.rules, nil)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestClassifierClassify(t *testing.T) {
+	c, err := NewClassifier([]Rule{
+		{
+			Name:      "bluetooth_disconnect",
+			Subsystem: "com.apple.bluetooth",
+			Match:     `(?i)disconnected.*device (?P<device>\S+)(?: reason (?P<reason>\d+))?`,
+			Fields:    []string{"device"},
+		},
+		{
+			Name:    "usb_attach",
+			Process: "kernel",
+			Match:   `USB device attached`,
+		},
+	}, nil)
+	if err != nil {
+		t.Fatalf("NewClassifier: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		entry      LogEntry
+		wantType   string
+		wantFields map[string]string
+	}{
+		{
+			name:       "rule with named group",
+			entry:      LogEntry{Subsystem: "com.apple.bluetooth", Process: "bluetoothd", Message: "Disconnected from device AA:BB reason 19"},
+			wantType:   "bluetooth_disconnect",
+			wantFields: map[string]string{"device": "AA:BB"},
+		},
+		{
+			name:  "rule subsystem mismatch",
+			entry: LogEntry{Subsystem: "com.apple.wifi", Message: "Disconnected from device AA:BB"},
+		},
+		{
+			name:     "rule by process",
+			entry:    LogEntry{Process: "kernel", Subsystem: "com.apple.iokit", Message: "USB device attached"},
+			wantType: "usb_attach",
+		},
+		{
+			name:     "builtin powerd",
+			entry:    LogEntry{Subsystem: "com.apple.powerd", Message: "Wake Reason: EC.LidOpen"},
+			wantType: EventWake,
+		},
+		{
+			name:  "builtin ignores other subsystems",
+			entry: LogEntry{Subsystem: "com.apple.bluetooth", Message: "Wake Reason: EC.LidOpen"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := c.Classify(&tt.entry)
+			if tt.wantType == "" {
+				if got != nil {
+					t.Fatalf("expected no event, got %+v", got)
+				}
+				return
+			}
+			if got == nil {
+				t.Fatalf("expected %q event, got nil", tt.wantType)
+			}
+			if got.Type != tt.wantType {
+				t.Errorf("Type = %q, want %q", got.Type, tt.wantType)
+			}
+			if len(got.Fields) != len(tt.wantFields) {
+				t.Fatalf("Fields = %v, want %v", got.Fields, tt.wantFields)
+			}
+			for k, v := range tt.wantFields {
+				if got.Fields[k] != v {
+					t.Errorf("Fields[%q] = %q, want %q", k, got.Fields[k], v)
+				}
+			}
+		})
+	}
+}
+
+func TestClassifierPredicate(t *testing.T) {
+	var nilClassifier *Classifier
+	if got, want := nilClassifier.Predicate(), `subsystem == "com.apple.powerd"`; got != want {
+		t.Errorf("nil Predicate() = %q, want %q", got, want)
+	}
+
+	c, err := NewClassifier([]Rule{
+		{Name: "a", Subsystem: "com.apple.bluetooth", Match: "x"},
+		{Name: "b", Process: "kernel", Match: "y"},
+		{Name: "c", Subsystem: "com.apple.bluetooth", Match: "z"},
+		{Name: "d", Match: "any"},
+	}, nil)
+	if err != nil {
+		t.Fatalf("NewClassifier: %v", err)
+	}
+
+	want := `subsystem == "com.apple.powerd" OR process == "kernel" OR subsystem == "com.apple.bluetooth"`
+	if got := c.Predicate(); got != want {
+		t.Errorf("Predicate() = %q, want %q", got, want)
+	}
+}
+
+func TestClassifierHasType(t *testing.T) {
+	c, err := NewClassifier([]Rule{{Name: "bluetooth_disconnect", Match: "x"}}, nil)
+	if err != nil {
+		t.Fatalf("NewClassifier: %v", err)
+	}
+
+	for _, typ := range []string{EventWake, EventThermal, "bluetooth_disconnect"} {
+		if !c.HasType(typ) {
+			t.Errorf("HasType(%q) = false, want true", typ)
+		}
+	}
+	if c.HasType("nope") {
+		t.Error("HasType(\"nope\") = true, want false")
+	}
+}
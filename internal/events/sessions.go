@@ -0,0 +1,142 @@
+package events
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/lu-zhengda/macctl/internal/power"
+)
+
+// DefaultBatteryTolerance is how far a power snapshot may be from a sleep's
+// start or end and still be used for its battery reading.
+const DefaultBatteryTolerance = 30 * time.Minute
+
+// SleepSession is a sleep interval paired from a sleep event and the next
+// full wake.
+type SleepSession struct {
+	Start           time.Time `json:"start"`
+	End             time.Time `json:"end"`
+	DurationSeconds int64     `json:"duration_seconds"`
+	SleepReason     string    `json:"sleep_reason,omitempty"`
+	WakeReason      string    `json:"wake_reason,omitempty"`
+	WakeCategory    string    `json:"wake_category,omitempty"`
+	DarkWakes       int       `json:"dark_wakes"`
+	BatteryStart    *int      `json:"battery_start_pct,omitempty"`
+	BatteryEnd      *int      `json:"battery_end_pct,omitempty"`
+	BatteryDrop     *int      `json:"battery_drop_pct,omitempty"`
+}
+
+// Duration returns the length of the sleep session.
+func (s SleepSession) Duration() time.Duration {
+	return time.Duration(s.DurationSeconds) * time.Second
+}
+
+// IsDarkWake reports whether a wake event is a DarkWake, where the system
+// wakes briefly for maintenance or push without turning on the display.
+// A "DarkWake to FullWake" transition counts as a full wake.
+func IsDarkWake(e Event) bool {
+	text := e.Message
+	if text == "" {
+		text = e.Detail
+	}
+	lower := strings.ToLower(text)
+	return strings.Contains(lower, "darkwake") && !strings.Contains(lower, "fullwake")
+}
+
+// isExplicitFullWake reports whether a wake event names a full wake, such as
+// "DarkWake to FullWake" or powerd's "Wake from Deep Idle", rather than only
+// logging a wake reason.
+func isExplicitFullWake(e Event) bool {
+	text := e.Message
+	if text == "" {
+		text = e.Detail
+	}
+	lower := strings.ToLower(text)
+	return strings.Contains(lower, "fullwake") || strings.HasPrefix(lower, "wake from") ||
+		strings.Contains(lower, "display wake")
+}
+
+// PairSleepSessions pairs each sleep event with the next full wake. DarkWakes
+// in between are counted, and repeated sleep events after a DarkWake extend
+// the same session. While in a DarkWake, only an explicit full wake ends the
+// session; other wake lines, such as "Wake reason", belong to the DarkWake.
+// A trailing sleep with no wake yet is not reported.
+func PairSleepSessions(events []Event) []SleepSession {
+	sorted := append([]Event(nil), events...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Timestamp.Before(sorted[j].Timestamp)
+	})
+
+	var sessions []SleepSession
+	var current *SleepSession
+	inDarkWake := false
+
+	for _, e := range sorted {
+		switch e.Type {
+		case EventSleep:
+			inDarkWake = false
+			if current == nil {
+				current = &SleepSession{Start: e.Timestamp, SleepReason: e.Reason}
+			}
+		case EventWake:
+			if current == nil {
+				continue
+			}
+			if IsDarkWake(e) {
+				current.DarkWakes++
+				inDarkWake = true
+				continue
+			}
+			if inDarkWake && !isExplicitFullWake(e) {
+				continue
+			}
+			inDarkWake = false
+			current.End = e.Timestamp
+			current.DurationSeconds = int64(current.End.Sub(current.Start).Seconds())
+			current.WakeReason = e.Reason
+			current.WakeCategory = e.ReasonCategory
+			sessions = append(sessions, *current)
+			current = nil
+		}
+	}
+
+	return sessions
+}
+
+// AttachBattery fills in battery percentages at the start and end of each
+// session from the nearest power snapshot before the start and after the
+// end, within tolerance.
+func AttachBattery(sessions []SleepSession, snapshots []power.Snapshot, tolerance time.Duration) {
+	sorted := append([]power.Snapshot(nil), snapshots...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Timestamp.Before(sorted[j].Timestamp)
+	})
+
+	for i := range sessions {
+		s := &sessions[i]
+
+		// Last snapshot at or before the start.
+		idx := sort.Search(len(sorted), func(j int) bool {
+			return sorted[j].Timestamp.After(s.Start)
+		})
+		if idx > 0 && s.Start.Sub(sorted[idx-1].Timestamp) <= tolerance {
+			pct := sorted[idx-1].BatteryPct
+			s.BatteryStart = &pct
+		}
+
+		// First snapshot at or after the end.
+		idx = sort.Search(len(sorted), func(j int) bool {
+			return !sorted[j].Timestamp.Before(s.End)
+		})
+		if idx < len(sorted) && sorted[idx].Timestamp.Sub(s.End) <= tolerance {
+			pct := sorted[idx].BatteryPct
+			s.BatteryEnd = &pct
+		}
+
+		if s.BatteryStart != nil && s.BatteryEnd != nil {
+			drop := *s.BatteryStart - *s.BatteryEnd
+			s.BatteryDrop = &drop
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package events
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lu-zhengda/macctl/internal/power"
+)
+
+func TestPairSleepSessions(t *testing.T) {
+	base := time.Date(2025, 1, 14, 23, 0, 0, 0, time.UTC)
+	evts := []Event{
+		// A stray wake before any sleep is ignored.
+		{Timestamp: base.Add(-time.Hour), Type: EventWake, Message: "Wake reason: UserActivity"},
+		{Timestamp: base, Type: EventSleep, Reason: "Clamshell Sleep", Message: "Entering sleep reason: Clamshell Sleep"},
+		{Timestamp: base.Add(2 * time.Hour), Type: EventWake, Message: "DarkWake from Deep Idle [CDN] : due to SMC.OutboxNotEmpty/"},
+		{Timestamp: base.Add(2*time.Hour + time.Minute), Type: EventSleep, Message: "Entering sleep reason: Maintenance Sleep"},
+		{Timestamp: base.Add(4 * time.Hour), Type: EventWake, Message: "DarkWake from Deep Idle"},
+		// A wake reason logged during the DarkWake doesn't end the session.
+		{Timestamp: base.Add(4*time.Hour + time.Second), Type: EventWake, Reason: "RTC/Maintenance", ReasonCategory: ReasonRTC, Message: "Wake reason: RTC/Maintenance"},
+		{Timestamp: base.Add(4*time.Hour + 2*time.Minute), Type: EventSleep, Message: "Entering sleep reason: Maintenance Sleep"},
+		{Timestamp: base.Add(8 * time.Hour), Type: EventWake, Reason: "EC.LidOpen", ReasonCategory: ReasonLid, Message: `Wake reason: "EC.LidOpen"`},
+		// A duplicate wake line right after the full wake doesn't start anything.
+		{Timestamp: base.Add(8*time.Hour + time.Second), Type: EventWake, Message: "FullWake reason: UserActivity"},
+		// A second session.
+		{Timestamp: base.Add(10 * time.Hour), Type: EventSleep, Reason: "Idle Sleep"},
+		{Timestamp: base.Add(11 * time.Hour), Type: EventWake, Reason: "EC.PowerButton", ReasonCategory: ReasonPowerButton},
+		// A trailing sleep with no wake is not reported.
+		{Timestamp: base.Add(12 * time.Hour), Type: EventSleep},
+	}
+
+	sessions := PairSleepSessions(evts)
+	if len(sessions) != 2 {
+		t.Fatalf("expected 2 sessions, got %d: %+v", len(sessions), sessions)
+	}
+
+	s := sessions[0]
+	if !s.Start.Equal(base) || !s.End.Equal(base.Add(8*time.Hour)) {
+		t.Errorf("session 0 = %v..%v, want %v..%v", s.Start, s.End, base, base.Add(8*time.Hour))
+	}
+	if s.Duration() != 8*time.Hour {
+		t.Errorf("session 0 Duration = %v, want 8h", s.Duration())
+	}
+	if s.DarkWakes != 2 {
+		t.Errorf("session 0 DarkWakes = %d, want 2", s.DarkWakes)
+	}
+	if s.SleepReason != "Clamshell Sleep" || s.WakeReason != "EC.LidOpen" || s.WakeCategory != ReasonLid {
+		t.Errorf("session 0 reasons = %q/%q/%q", s.SleepReason, s.WakeReason, s.WakeCategory)
+	}
+
+	if sessions[1].DarkWakes != 0 || sessions[1].Duration() != time.Hour {
+		t.Errorf("session 1 = %+v, want 1h with no DarkWakes", sessions[1])
+	}
+}
+
+func TestPairSleepSessionsDarkWakeToFullWake(t *testing.T) {
+	base := time.Date(2025, 1, 14, 23, 0, 0, 0, time.UTC)
+	evts := []Event{
+		{Timestamp: base, Type: EventSleep, Reason: "Idle Sleep"},
+		{Timestamp: base.Add(time.Hour), Type: EventWake, Message: "DarkWake from Deep Idle [CDN] : due to SMC.OutboxNotEmpty/"},
+		{Timestamp: base.Add(time.Hour + time.Second), Type: EventWake, Message: "Wake reason: SMC.OutboxNotEmpty"},
+		{Timestamp: base.Add(time.Hour + time.Minute), Type: EventWake, Reason: "UserActivity", Message: "DarkWake to FullWake from Deep Idle [CDNVA] : due to UserActivity"},
+	}
+
+	sessions := PairSleepSessions(evts)
+	if len(sessions) != 1 {
+		t.Fatalf("expected 1 session, got %d: %+v", len(sessions), sessions)
+	}
+	if s := sessions[0]; !s.End.Equal(base.Add(time.Hour+time.Minute)) || s.DarkWakes != 1 || s.WakeReason != "UserActivity" {
+		t.Errorf("session = %+v, want end at the FullWake with 1 DarkWake", s)
+	}
+}
+
+func TestAttachBattery(t *testing.T) {
+	base := time.Date(2025, 1, 14, 23, 0, 0, 0, time.UTC)
+	sessions := []SleepSession{
+		{Start: base, End: base.Add(8 * time.Hour)},
+		{Start: base.Add(20 * time.Hour), End: base.Add(21 * time.Hour)},
+	}
+	snapshots := []power.Snapshot{
+		{Timestamp: base.Add(8*time.Hour + 2*time.Minute), BatteryPct: 71},
+		{Timestamp: base.Add(-5 * time.Minute), BatteryPct: 76},
+		// Too far from the second session's start to be used.
+		{Timestamp: base.Add(18 * time.Hour), BatteryPct: 90},
+		{Timestamp: base.Add(21 * time.Hour), BatteryPct: 88},
+	}
+
+	AttachBattery(sessions, snapshots, DefaultBatteryTolerance)
+
+	s := sessions[0]
+	if s.BatteryStart == nil || *s.BatteryStart != 76 {
+		t.Errorf("session 0 BatteryStart = %v, want 76", s.BatteryStart)
+	}
+	if s.BatteryEnd == nil || *s.BatteryEnd != 71 {
+		t.Errorf("session 0 BatteryEnd = %v, want 71", s.BatteryEnd)
+	}
+	if s.BatteryDrop == nil || *s.BatteryDrop != 5 {
+		t.Errorf("session 0 BatteryDrop = %v, want 5", s.BatteryDrop)
+	}
+
+	s = sessions[1]
+	if s.BatteryStart != nil {
+		t.Errorf("session 1 BatteryStart = %d, want nil", *s.BatteryStart)
+	}
+	if s.BatteryEnd == nil || *s.BatteryEnd != 88 {
+		t.Errorf("session 1 BatteryEnd = %v, want 88", s.BatteryEnd)
+	}
+	if s.BatteryDrop != nil {
+		t.Errorf("session 1 BatteryDrop = %d, want nil", *s.BatteryDrop)
+	}
+}
+
+func TestIsDarkWake(t *testing.T) {
+	tests := []struct {
+		message string
+		want    bool
+	}{
+		{message: "DarkWake from Deep Idle [CDN] : due to SMC.OutboxNotEmpty/", want: true},
+		{message: "DarkWake to FullWake from Deep Idle [CDNVA] : due to UserActivity", want: false},
+		{message: `Wake reason: "EC.LidOpen"`, want: false},
+	}
+
+	for _, tt := range tests {
+		if got := IsDarkWake(Event{Type: EventWake, Message: tt.message}); got != tt.want {
+			t.Errorf("IsDarkWake(%q) = %v, want %v", tt.message, got, tt.want)
+		}
+	}
+}
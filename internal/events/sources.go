@@ -0,0 +1,223 @@
+package events
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Event source names.
+const (
+	SourcePower     = "power"
+	SourceDisplay   = "display"
+	SourceAudio     = "audio"
+	SourceBluetooth = "bluetooth"
+	SourceWiFi      = "wifi"
+	SourceFocus     = "focus"
+
+	// SourceCustom marks events produced by user-defined rules.
+	SourceCustom = "custom"
+)
+
+// Event types produced by the non-power sources.
+const (
+	EventDisplayConnect      = "display_connect"
+	EventDisplayDisconnect   = "display_disconnect"
+	EventAudioDeviceAdded    = "audio_device_added"
+	EventAudioDeviceRemoved  = "audio_device_removed"
+	EventAudioDefaultChanged = "audio_default_changed"
+	EventBluetoothConnect    = "bluetooth_connect"
+	EventBluetoothDisconnect = "bluetooth_disconnect"
+	EventWiFiAssociate       = "wifi_associate"
+	EventWiFiDisassociate    = "wifi_disassociate"
+	EventFocusOn             = "focus_on"
+	EventFocusOff            = "focus_off"
+)
+
+// DefaultSources is the source list used when none is given.
+var DefaultSources = []string{SourcePower}
+
+// sourcePattern maps log messages matching re to an event type.
+type sourcePattern struct {
+	eventType string
+	re        *regexp.Regexp
+}
+
+// source collects events from one subsystem's log entries.
+type source struct {
+	name       string
+	subsystems []string
+	processes  []string
+	types      []string
+
+	// classify returns the event for a matching entry, or nil. If nil,
+	// patterns are tried in order.
+	classify func(*LogEntry) *Event
+	patterns []sourcePattern
+}
+
+// Order matters within each pattern list: "disconnected" must be tried
+// before "connected", "removed" before "added", and so on.
+var sources = []source{
+	{
+		name:       SourcePower,
+		subsystems: []string{"com.apple.powerd"},
+		types:      []string{EventWake, EventSleep, EventLidOpen, EventLidClose, EventThermal, EventPowerSource},
+		classify:   classifyEntry,
+	},
+	{
+		name:      SourceDisplay,
+		processes: []string{"WindowServer"},
+		types:     []string{EventDisplayConnect, EventDisplayDisconnect},
+		patterns: []sourcePattern{
+			{EventDisplayDisconnect, regexp.MustCompile(`(?i)display.*\b(removed|disconnected|detached)\b`)},
+			{EventDisplayConnect, regexp.MustCompile(`(?i)display.*\b(added|connected|attached)\b`)},
+		},
+	},
+	{
+		name:       SourceAudio,
+		subsystems: []string{"com.apple.coreaudio"},
+		processes:  []string{"coreaudiod"},
+		types:      []string{EventAudioDeviceAdded, EventAudioDeviceRemoved, EventAudioDefaultChanged},
+		patterns: []sourcePattern{
+			{EventAudioDefaultChanged, regexp.MustCompile(`(?i)default (output|input|system output) device (changed|is now)`)},
+			{EventAudioDeviceRemoved, regexp.MustCompile(`(?i)device.*\b(removed|disconnected)\b`)},
+			{EventAudioDeviceAdded, regexp.MustCompile(`(?i)device.*\b(added|connected)\b`)},
+		},
+	},
+	{
+		name:       SourceBluetooth,
+		subsystems: []string{"com.apple.bluetooth"},
+		types:      []string{EventBluetoothConnect, EventBluetoothDisconnect},
+		patterns: []sourcePattern{
+			{EventBluetoothDisconnect, regexp.MustCompile(`(?i)\bdisconnect(ed|ion complete)\b`)},
+			{EventBluetoothConnect, regexp.MustCompile(`(?i)\bconnect(ed|ion complete)\b`)},
+		},
+	},
+	{
+		name:       SourceWiFi,
+		subsystems: []string{"com.apple.wifi"},
+		processes:  []string{"airportd"},
+		types:      []string{EventWiFiAssociate, EventWiFiDisassociate},
+		patterns: []sourcePattern{
+			{EventWiFiDisassociate, regexp.MustCompile(`(?i)\b(disassociat\w*|link down)\b`)},
+			{EventWiFiAssociate, regexp.MustCompile(`(?i)\b(associated (to|with)|link up)\b`)},
+		},
+	},
+	{
+		name:       SourceFocus,
+		subsystems: []string{"com.apple.donotdisturb"},
+		types:      []string{EventFocusOn, EventFocusOff},
+		patterns: []sourcePattern{
+			{EventFocusOff, regexp.MustCompile(`(?i)\b(mode|assertion)\b.*\b(deactivated|invalidated|ended|disabled)\b`)},
+			{EventFocusOn, regexp.MustCompile(`(?i)\b(mode|assertion)\b.*\b(activated|taken|started|enabled)\b`)},
+		},
+	},
+}
+
+// SourceNames returns the names of all event sources.
+func SourceNames() []string {
+	names := make([]string, len(sources))
+	for i, s := range sources {
+		names[i] = s.name
+	}
+	return names
+}
+
+// ParseSources parses a comma-separated source list like "display,audio".
+// "all" selects every source.
+func ParseSources(s string) ([]string, error) {
+	if strings.TrimSpace(s) == "" {
+		return DefaultSources, nil
+	}
+
+	seen := make(map[string]bool)
+	var names []string
+	for _, name := range strings.Split(s, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		if name == "all" {
+			return SourceNames(), nil
+		}
+		if lookupSource(name) == nil {
+			return nil, fmt.Errorf("unknown event source %q (use %s, or all)", name, strings.Join(SourceNames(), ", "))
+		}
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+
+	if len(names) == 0 {
+		return nil, fmt.Errorf("no event sources given")
+	}
+	return names, nil
+}
+
+func lookupSource(name string) *source {
+	for i := range sources {
+		if sources[i].name == name {
+			return &sources[i]
+		}
+	}
+	return nil
+}
+
+// clauses returns the `log` predicate clauses selecting the source's entries.
+func (s *source) clauses() []string {
+	var clauses []string
+	for _, sub := range s.subsystems {
+		clauses = append(clauses, "subsystem == "+strconv.Quote(sub))
+	}
+	for _, p := range s.processes {
+		clauses = append(clauses, "process == "+strconv.Quote(p))
+	}
+	return clauses
+}
+
+// matches reports whether entry comes from one of the source's subsystems or
+// processes. Entries with no subsystem come from the legacy compact format,
+// which is only queried for powerd.
+func (s *source) matches(entry *LogEntry) bool {
+	if entry.Subsystem == "" && entry.Process == "" {
+		return s.name == SourcePower
+	}
+	for _, sub := range s.subsystems {
+		if entry.Subsystem == sub {
+			return true
+		}
+	}
+	for _, p := range s.processes {
+		if entry.Process == p {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *source) apply(entry *LogEntry) *Event {
+	if !s.matches(entry) {
+		return nil
+	}
+
+	var event *Event
+	if s.classify != nil {
+		event = s.classify(entry)
+	} else {
+		for _, p := range s.patterns {
+			if p.re.MatchString(entry.Message) {
+				event = newEvent(entry)
+				event.Type = p.eventType
+				break
+			}
+		}
+	}
+
+	if event != nil {
+		event.Source = s.name
+	}
+	return event
+}
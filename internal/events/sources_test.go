@@ -0,0 +1,168 @@
+package events
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseSources(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    []string
+		wantErr bool
+	}{
+		{input: "", want: DefaultSources},
+		{input: "display,audio", want: []string{SourceDisplay, SourceAudio}},
+		{input: " WiFi , wifi ,focus", want: []string{SourceWiFi, SourceFocus}},
+		{input: "all", want: SourceNames()},
+		{input: "printer", wantErr: true},
+		{input: ",", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseSources(tt.input)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got %v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseSources(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClassifierSources(t *testing.T) {
+	c, err := NewClassifier(nil, SourceNames())
+	if err != nil {
+		t.Fatalf("NewClassifier: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		entry      LogEntry
+		wantSource string
+		wantType   string
+	}{
+		{
+			name:       "powerd wake",
+			entry:      LogEntry{Subsystem: "com.apple.powerd", Process: "powerd", Message: "Wake Reason: EC.LidOpen"},
+			wantSource: SourcePower,
+			wantType:   EventWake,
+		},
+		{
+			name:       "display added",
+			entry:      LogEntry{Process: "WindowServer", Message: "Display 0x4280a41 added"},
+			wantSource: SourceDisplay,
+			wantType:   EventDisplayConnect,
+		},
+		{
+			name:       "display removed",
+			entry:      LogEntry{Process: "WindowServer", Message: "Display 0x4280a41 removed"},
+			wantSource: SourceDisplay,
+			wantType:   EventDisplayDisconnect,
+		},
+		{
+			name:       "audio default output",
+			entry:      LogEntry{Subsystem: "com.apple.coreaudio", Process: "coreaudiod", Message: "Default output device changed to AirPods Pro"},
+			wantSource: SourceAudio,
+			wantType:   EventAudioDefaultChanged,
+		},
+		{
+			name:       "audio device removed",
+			entry:      LogEntry{Process: "coreaudiod", Message: "Device AirPods Pro removed"},
+			wantSource: SourceAudio,
+			wantType:   EventAudioDeviceRemoved,
+		},
+		{
+			name:       "bluetooth disconnect",
+			entry:      LogEntry{Subsystem: "com.apple.bluetooth", Process: "bluetoothd", Message: "Device AA:BB:CC disconnected"},
+			wantSource: SourceBluetooth,
+			wantType:   EventBluetoothDisconnect,
+		},
+		{
+			name:       "bluetooth connect",
+			entry:      LogEntry{Subsystem: "com.apple.bluetooth", Process: "bluetoothd", Message: "Device AA:BB:CC connected"},
+			wantSource: SourceBluetooth,
+			wantType:   EventBluetoothConnect,
+		},
+		{
+			name:       "wifi associate",
+			entry:      LogEntry{Subsystem: "com.apple.wifi", Process: "airportd", Message: "Associated to network HomeNet"},
+			wantSource: SourceWiFi,
+			wantType:   EventWiFiAssociate,
+		},
+		{
+			name:       "wifi disassociate",
+			entry:      LogEntry{Process: "airportd", Message: "Disassociated from HomeNet, reason 8"},
+			wantSource: SourceWiFi,
+			wantType:   EventWiFiDisassociate,
+		},
+		{
+			name:       "focus on",
+			entry:      LogEntry{Subsystem: "com.apple.donotdisturb", Message: "Mode Work activated"},
+			wantSource: SourceFocus,
+			wantType:   EventFocusOn,
+		},
+		{
+			name:       "focus off",
+			entry:      LogEntry{Subsystem: "com.apple.donotdisturb", Message: "Mode Work deactivated"},
+			wantSource: SourceFocus,
+			wantType:   EventFocusOff,
+		},
+		{
+			name:  "unrelated bluetooth message",
+			entry: LogEntry{Subsystem: "com.apple.bluetooth", Message: "Scanning for devices"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := c.Classify(&tt.entry)
+			if tt.wantType == "" {
+				if got != nil {
+					t.Fatalf("expected no event, got %+v", got)
+				}
+				return
+			}
+			if got == nil {
+				t.Fatalf("expected %s/%s event, got nil", tt.wantSource, tt.wantType)
+			}
+			if got.Source != tt.wantSource || got.Type != tt.wantType {
+				t.Errorf("got %s/%s, want %s/%s", got.Source, got.Type, tt.wantSource, tt.wantType)
+			}
+		})
+	}
+}
+
+func TestClassifierSourceSelection(t *testing.T) {
+	c, err := NewClassifier(nil, []string{SourceDisplay})
+	if err != nil {
+		t.Fatalf("NewClassifier: %v", err)
+	}
+
+	wake := LogEntry{Subsystem: "com.apple.powerd", Message: "Wake Reason: EC.LidOpen"}
+	if got := c.Classify(&wake); got != nil {
+		t.Errorf("expected powerd entry to be ignored, got %+v", got)
+	}
+
+	if want := `process == "WindowServer"`; c.Predicate() != want {
+		t.Errorf("Predicate() = %q, want %q", c.Predicate(), want)
+	}
+	if c.HasType(EventWake) {
+		t.Error("HasType(wake) = true for display-only classifier")
+	}
+	if !c.HasType(EventDisplayConnect) {
+		t.Error("HasType(display_connect) = false for display classifier")
+	}
+
+	if _, err := NewClassifier(nil, []string{"printer"}); err == nil {
+		t.Error("expected error for unknown source")
+	}
+}
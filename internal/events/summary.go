@@ -0,0 +1,124 @@
+package events
+
+import (
+	"sort"
+	"time"
+)
+
+// dayLayout is the format of summary and heatmap dates, in local time.
+const dayLayout = "2006-01-02"
+
+// HeatmapTypes are the event types summaries chart by hour of day.
+var HeatmapTypes = []string{EventWake, EventThermal}
+
+// TypeCount is the number of events of one type.
+type TypeCount struct {
+	Type  string `json:"type"`
+	Count int    `json:"count"`
+}
+
+// DaySummary counts one local calendar day's events by type.
+type DaySummary struct {
+	Date  string         `json:"date"`
+	Total int            `json:"total"`
+	Types map[string]int `json:"types,omitempty"`
+}
+
+// HeatmapDay holds one day's event counts per local hour.
+type HeatmapDay struct {
+	Date  string  `json:"date"`
+	Hours [24]int `json:"hours"`
+}
+
+// Heatmap counts one event type by hour of day for every day in a summary.
+type Heatmap struct {
+	Type  string       `json:"type"`
+	Total int          `json:"total"`
+	Max   int          `json:"max"`
+	Days  []HeatmapDay `json:"days"`
+}
+
+// Summary groups events by day and type over a time window.
+type Summary struct {
+	Since    time.Time    `json:"since"`
+	Until    time.Time    `json:"until"`
+	Total    int          `json:"total"`
+	Types    []TypeCount  `json:"types"`
+	Days     []DaySummary `json:"days"`
+	Heatmaps []Heatmap    `json:"heatmaps"`
+}
+
+// Summarize counts events in [since, until] by local day and type, and
+// builds hour-of-day heatmaps for the given types. Every day in the window
+// appears, including days without events. Each event counts once, so bursts
+// should be deduplicated first.
+func Summarize(events []Event, since, until time.Time, heatmapTypes []string) *Summary {
+	s := &Summary{Since: since, Until: until}
+
+	var dates []string
+	dayIndex := make(map[string]int)
+	for d := startOfDay(since.Local()); !d.After(until.Local()); d = d.AddDate(0, 0, 1) {
+		date := d.Format(dayLayout)
+		dayIndex[date] = len(dates)
+		dates = append(dates, date)
+		s.Days = append(s.Days, DaySummary{Date: date})
+	}
+
+	heatIndex := make(map[string]int)
+	for i, t := range heatmapTypes {
+		heatIndex[t] = i
+		h := Heatmap{Type: t, Days: make([]HeatmapDay, len(dates))}
+		for j, date := range dates {
+			h.Days[j].Date = date
+		}
+		s.Heatmaps = append(s.Heatmaps, h)
+	}
+
+	typeTotals := make(map[string]int)
+	for _, e := range events {
+		if e.Timestamp.Before(since) || e.Timestamp.After(until) {
+			continue
+		}
+		local := e.Timestamp.Local()
+		di, ok := dayIndex[local.Format(dayLayout)]
+		if !ok {
+			continue
+		}
+
+		day := &s.Days[di]
+		if day.Types == nil {
+			day.Types = make(map[string]int)
+		}
+		// A collapsed event stands for Count occurrences.
+		n := max(e.Count, 1)
+		day.Types[e.Type] += n
+		day.Total += n
+		s.Total += n
+		typeTotals[e.Type] += n
+
+		if hi, ok := heatIndex[e.Type]; ok {
+			h := &s.Heatmaps[hi]
+			h.Days[di].Hours[local.Hour()] += n
+			h.Total += n
+			if n := h.Days[di].Hours[local.Hour()]; n > h.Max {
+				h.Max = n
+			}
+		}
+	}
+
+	for t, n := range typeTotals {
+		s.Types = append(s.Types, TypeCount{Type: t, Count: n})
+	}
+	sort.Slice(s.Types, func(i, j int) bool {
+		if s.Types[i].Count != s.Types[j].Count {
+			return s.Types[i].Count > s.Types[j].Count
+		}
+		return s.Types[i].Type < s.Types[j].Type
+	})
+
+	return s
+}
+
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
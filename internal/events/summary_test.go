@@ -0,0 +1,86 @@
+package events
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSummarize(t *testing.T) {
+	since := time.Date(2025, 1, 13, 12, 0, 0, 0, time.Local)
+	until := time.Date(2025, 1, 15, 12, 0, 0, 0, time.Local)
+
+	events := []Event{
+		{Timestamp: time.Date(2025, 1, 13, 23, 10, 0, 0, time.Local), Type: EventWake},
+		{Timestamp: time.Date(2025, 1, 13, 23, 40, 0, 0, time.Local), Type: EventWake},
+		{Timestamp: time.Date(2025, 1, 13, 23, 50, 0, 0, time.Local), Type: EventSleep},
+		{Timestamp: time.Date(2025, 1, 15, 9, 5, 0, 0, time.Local), Type: EventThermal},
+		{Timestamp: time.Date(2025, 1, 15, 9, 30, 0, 0, time.Local), Type: EventWake},
+		// Outside the window.
+		{Timestamp: time.Date(2025, 1, 12, 8, 0, 0, 0, time.Local), Type: EventWake},
+		{Timestamp: time.Date(2025, 1, 15, 13, 0, 0, 0, time.Local), Type: EventWake},
+	}
+
+	s := Summarize(events, since, until, HeatmapTypes)
+
+	if s.Total != 5 {
+		t.Errorf("Total = %d, want 5", s.Total)
+	}
+
+	wantDates := []string{"2025-01-13", "2025-01-14", "2025-01-15"}
+	if len(s.Days) != len(wantDates) {
+		t.Fatalf("got %d days, want %d", len(s.Days), len(wantDates))
+	}
+	for i, want := range wantDates {
+		if s.Days[i].Date != want {
+			t.Errorf("Days[%d].Date = %q, want %q", i, s.Days[i].Date, want)
+		}
+	}
+	if s.Days[0].Total != 3 || s.Days[0].Types[EventWake] != 2 || s.Days[0].Types[EventSleep] != 1 {
+		t.Errorf("Days[0] = %+v", s.Days[0])
+	}
+	if s.Days[1].Total != 0 || s.Days[1].Types != nil {
+		t.Errorf("Days[1] should be empty, got %+v", s.Days[1])
+	}
+
+	if len(s.Types) == 0 || s.Types[0].Type != EventWake || s.Types[0].Count != 3 {
+		t.Errorf("Types = %+v, want wake first with 3", s.Types)
+	}
+
+	if len(s.Heatmaps) != 2 {
+		t.Fatalf("got %d heatmaps, want 2", len(s.Heatmaps))
+	}
+	wakes := s.Heatmaps[0]
+	if wakes.Type != EventWake || wakes.Total != 3 || wakes.Max != 2 {
+		t.Errorf("wake heatmap = type %q total %d max %d", wakes.Type, wakes.Total, wakes.Max)
+	}
+	if wakes.Days[0].Hours[23] != 2 || wakes.Days[2].Hours[9] != 1 {
+		t.Errorf("wake heatmap cells: day0[23]=%d day2[9]=%d", wakes.Days[0].Hours[23], wakes.Days[2].Hours[9])
+	}
+	thermal := s.Heatmaps[1]
+	if thermal.Type != EventThermal || thermal.Days[2].Hours[9] != 1 {
+		t.Errorf("thermal heatmap = %+v", thermal)
+	}
+}
+
+func TestSummarizeCountsCollapsedEvents(t *testing.T) {
+	since := time.Date(2025, 1, 15, 0, 0, 0, 0, time.Local)
+	until := time.Date(2025, 1, 15, 23, 0, 0, 0, time.Local)
+
+	events := []Event{
+		{Timestamp: time.Date(2025, 1, 15, 3, 0, 0, 0, time.Local), Type: EventWake, Count: 4},
+		{Timestamp: time.Date(2025, 1, 15, 3, 30, 0, 0, time.Local), Type: EventWake},
+	}
+
+	s := Summarize(events, since, until, HeatmapTypes)
+
+	if s.Total != 5 || s.Days[0].Types[EventWake] != 5 {
+		t.Errorf("Total = %d, wakes = %d; want 5, 5", s.Total, s.Days[0].Types[EventWake])
+	}
+	if len(s.Types) != 1 || s.Types[0].Count != 5 {
+		t.Errorf("Types = %+v, want wake with 5", s.Types)
+	}
+	wakes := s.Heatmaps[0]
+	if wakes.Total != 5 || wakes.Max != 5 || wakes.Days[0].Hours[3] != 5 {
+		t.Errorf("wake heatmap = total %d max %d hour3 %d, want 5", wakes.Total, wakes.Max, wakes.Days[0].Hours[3])
+	}
+}
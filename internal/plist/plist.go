@@ -0,0 +1,195 @@
+// Package plist decodes XML property lists, as printed by macOS tools such as
+// `diskutil -plist` and `tmutil -X`.
+package plist
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Decode parses an XML property list into Go values: dictionaries become
+// map[string]any, arrays []any, integers int64, reals float64, booleans
+// bool, dates time.Time, data []byte and strings string.
+func Decode(data []byte) (any, error) {
+	d := xml.NewDecoder(bytes.NewReader(data))
+	d.Strict = false
+
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse plist: %w", err)
+		}
+		start, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		if start.Name.Local == "plist" {
+			continue
+		}
+		v, err := decodeValue(d, start)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse plist: %w", err)
+		}
+		return v, nil
+	}
+}
+
+// Unmarshal decodes an XML property list into v. Values are matched to v's
+// fields by their JSON tags, which should name the plist keys. Dates decode
+// as RFC 3339 strings or time.Time, and data as base64 strings or []byte.
+func Unmarshal(data []byte, v any) error {
+	decoded, err := Decode(data)
+	if err != nil {
+		return err
+	}
+
+	// Round-trip through JSON so callers can describe plists with the same
+	// struct tags they use everywhere else.
+	raw, err := json.Marshal(decoded)
+	if err != nil {
+		return fmt.Errorf("failed to convert plist: %w", err)
+	}
+	if err := json.Unmarshal(raw, v); err != nil {
+		return fmt.Errorf("failed to decode plist: %w", err)
+	}
+	return nil
+}
+
+func decodeValue(d *xml.Decoder, start xml.StartElement) (any, error) {
+	switch start.Name.Local {
+	case "dict":
+		return decodeDict(d)
+	case "array":
+		return decodeArray(d)
+	case "true", "false":
+		if err := d.Skip(); err != nil {
+			return nil, err
+		}
+		return start.Name.Local == "true", nil
+	}
+
+	text, err := elementText(d)
+	if err != nil {
+		return nil, err
+	}
+
+	if start.Name.Local == "string" {
+		return text, nil
+	}
+	text = strings.TrimSpace(text)
+
+	switch start.Name.Local {
+	case "integer":
+		n, err := strconv.ParseInt(text, 10, 64)
+		if err != nil {
+			// Unsigned values above MaxInt64 are rare; keep them exact.
+			u, uerr := strconv.ParseUint(text, 10, 64)
+			if uerr != nil {
+				return nil, fmt.Errorf("invalid integer %q", text)
+			}
+			return u, nil
+		}
+		return n, nil
+	case "real":
+		f, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid real %q", text)
+		}
+		return f, nil
+	case "date":
+		t, err := time.Parse(time.RFC3339, text)
+		if err != nil {
+			return nil, fmt.Errorf("invalid date %q", text)
+		}
+		return t, nil
+	case "data":
+		b, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(text), ""))
+		if err != nil {
+			return nil, fmt.Errorf("invalid data: %w", err)
+		}
+		return b, nil
+	default:
+		return nil, fmt.Errorf("unknown plist element <%s>", start.Name.Local)
+	}
+}
+
+func decodeDict(d *xml.Decoder) (map[string]any, error) {
+	m := make(map[string]any)
+	var key string
+	haveKey := false
+
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return nil, err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if t.Name.Local == "key" {
+				key, err = elementText(d)
+				if err != nil {
+					return nil, err
+				}
+				haveKey = true
+				continue
+			}
+			if !haveKey {
+				return nil, fmt.Errorf("dict value <%s> without key", t.Name.Local)
+			}
+			v, err := decodeValue(d, t)
+			if err != nil {
+				return nil, err
+			}
+			m[key] = v
+			haveKey = false
+		case xml.EndElement:
+			return m, nil
+		}
+	}
+}
+
+func decodeArray(d *xml.Decoder) ([]any, error) {
+	arr := []any{}
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return nil, err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			v, err := decodeValue(d, t)
+			if err != nil {
+				return nil, err
+			}
+			arr = append(arr, v)
+		case xml.EndElement:
+			return arr, nil
+		}
+	}
+}
+
+// elementText returns the character data of the current element and
+// consumes its end tag.
+func elementText(d *xml.Decoder) (string, error) {
+	var b strings.Builder
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return "", err
+		}
+		switch t := tok.(type) {
+		case xml.CharData:
+			b.Write(t)
+		case xml.StartElement:
+			return "", fmt.Errorf("unexpected <%s> in text element", t.Name.Local)
+		case xml.EndElement:
+			return b.String(), nil
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package plist
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+const samplePlist = `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>DeviceIdentifier</key>
+	<string>disk0</string>
+	<key>Internal</key>
+	<true/>
+	<key>Removable</key>
+	<false/>
+	<key>Size</key>
+	<integer>500107862016</integer>
+	<key>Ratio</key>
+	<real>0.5</real>
+	<key>Date</key>
+	<date>2025-01-15T10:30:00Z</date>
+	<key>Blob</key>
+	<data>
+	aGVsbG8=
+	</data>
+	<key>Partitions</key>
+	<array>
+		<dict>
+			<key>DeviceIdentifier</key>
+			<string>disk0s1</string>
+		</dict>
+		<string> padded </string>
+	</array>
+	<key>Empty</key>
+	<array/>
+</dict>
+</plist>
+`
+
+func TestDecode(t *testing.T) {
+	v, err := Decode([]byte(samplePlist))
+	if err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+
+	want := map[string]any{
+		"DeviceIdentifier": "disk0",
+		"Internal":         true,
+		"Removable":        false,
+		"Size":             int64(500107862016),
+		"Ratio":            0.5,
+		"Date":             time.Date(2025, 1, 15, 10, 30, 0, 0, time.UTC),
+		"Blob":             []byte("hello"),
+		"Partitions": []any{
+			map[string]any{"DeviceIdentifier": "disk0s1"},
+			" padded ",
+		},
+		"Empty": []any{},
+	}
+	if !reflect.DeepEqual(v, want) {
+		t.Errorf("Decode =\n%#v\nwant\n%#v", v, want)
+	}
+}
+
+func TestUnmarshal(t *testing.T) {
+	var got struct {
+		DeviceIdentifier string    `json:"DeviceIdentifier"`
+		Internal         bool      `json:"Internal"`
+		Size             int64     `json:"Size"`
+		Date             time.Time `json:"Date"`
+		Blob             []byte    `json:"Blob"`
+		Partitions       []any     `json:"Partitions"`
+		Missing          string    `json:"Missing"`
+	}
+	if err := Unmarshal([]byte(samplePlist), &got); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+
+	if got.DeviceIdentifier != "disk0" || !got.Internal || got.Size != 500107862016 {
+		t.Errorf("got %+v", got)
+	}
+	if !got.Date.Equal(time.Date(2025, 1, 15, 10, 30, 0, 0, time.UTC)) {
+		t.Errorf("Date = %v", got.Date)
+	}
+	if string(got.Blob) != "hello" {
+		t.Errorf("Blob = %q, want %q", got.Blob, "hello")
+	}
+	if len(got.Partitions) != 2 {
+		t.Errorf("Partitions = %v", got.Partitions)
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{name: "empty", input: ""},
+		{name: "bad integer", input: `<plist><integer>abc</integer></plist>`},
+		{name: "unknown element", input: `<plist><widget/></plist>`},
+		{name: "value without key", input: `<plist><dict><string>x</string></dict></plist>`},
+		{name: "truncated", input: `<plist><dict><key>a</key>`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if v, err := Decode([]byte(tt.input)); err == nil {
+				t.Errorf("expected error, got %#v", v)
+			}
+		})
+	}
+}
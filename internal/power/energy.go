@@ -0,0 +1,125 @@
+package power
+
+import (
+	"sort"
+	"time"
+)
+
+// DefaultEnergyMaxGap is the longest gap between two snapshots that is still
+// integrated. Longer gaps (the recorder wasn't running, the Mac was off) are
+// skipped rather than extrapolated.
+const DefaultEnergyMaxGap = 30 * time.Minute
+
+// EnergyOptions controls how energy is accounted and priced.
+type EnergyOptions struct {
+	PricePerKWh       float64
+	CarbonGramsPerKWh float64
+	MaxGap            time.Duration
+	Location          *time.Location
+}
+
+// EnergyDay holds the energy drawn on a single calendar day.
+type EnergyDay struct {
+	Date        string  `json:"date"`
+	WallWh      float64 `json:"wall_wh"`
+	BatteryWh   float64 `json:"battery_wh"`
+	Cost        float64 `json:"cost,omitempty"`
+	CarbonGrams float64 `json:"carbon_grams,omitempty"`
+}
+
+// EnergyReport holds per-day and total energy consumption.
+type EnergyReport struct {
+	Days              []EnergyDay `json:"days"`
+	TotalWallWh       float64     `json:"total_wall_wh"`
+	TotalBatteryWh    float64     `json:"total_battery_wh"`
+	TotalCost         float64     `json:"total_cost,omitempty"`
+	TotalCarbonGrams  float64     `json:"total_carbon_grams,omitempty"`
+	PricePerKWh       float64     `json:"price_per_kwh,omitempty"`
+	CarbonGramsPerKWh float64     `json:"carbon_grams_per_kwh,omitempty"`
+	Samples           int         `json:"samples"`
+}
+
+// ComputeEnergy integrates recorded voltage and amperage into watt-hours
+// drawn from the wall and from the battery per day.
+//
+// Each interval between consecutive snapshots is attributed to the earlier
+// snapshot's power draw and calendar day. On AC power, wall draw is the
+// system input power when the battery reports it, otherwise the battery's
+// charging power (a lower bound). Only wall energy is priced, since battery
+// energy was itself drawn from the wall earlier.
+func ComputeEnergy(snapshots []Snapshot, opts EnergyOptions) *EnergyReport {
+	if opts.MaxGap <= 0 {
+		opts.MaxGap = DefaultEnergyMaxGap
+	}
+	loc := opts.Location
+	if loc == nil {
+		loc = time.Local
+	}
+
+	var samples []Snapshot
+	for _, s := range snapshots {
+		if s.VoltageMV > 0 {
+			samples = append(samples, s)
+		}
+	}
+	sort.SliceStable(samples, func(i, j int) bool {
+		return samples[i].Timestamp.Before(samples[j].Timestamp)
+	})
+
+	report := &EnergyReport{
+		PricePerKWh:       opts.PricePerKWh,
+		CarbonGramsPerKWh: opts.CarbonGramsPerKWh,
+		Samples:           len(samples),
+	}
+
+	byDay := make(map[string]*EnergyDay)
+	for i := 0; i+1 < len(samples); i++ {
+		cur := samples[i]
+		dt := samples[i+1].Timestamp.Sub(cur.Timestamp)
+		if dt <= 0 || dt > opts.MaxGap {
+			continue
+		}
+		hours := dt.Hours()
+
+		// mV * mA = µW.
+		batteryW := float64(cur.VoltageMV) * float64(cur.AmperageMA) / 1e6
+
+		var wallWh, battWh float64
+		if batteryW < 0 {
+			battWh = -batteryW * hours
+		}
+		if cur.ExternalConnected {
+			switch {
+			case cur.SystemPowerMW > 0:
+				wallWh = float64(cur.SystemPowerMW) / 1e3 * hours
+			case batteryW > 0:
+				wallWh = batteryW * hours
+			}
+		}
+
+		date := cur.Timestamp.In(loc).Format("2006-01-02")
+		day, ok := byDay[date]
+		if !ok {
+			day = &EnergyDay{Date: date}
+			byDay[date] = day
+		}
+		day.WallWh += wallWh
+		day.BatteryWh += battWh
+	}
+
+	for _, day := range byDay {
+		day.Cost = day.WallWh / 1e3 * opts.PricePerKWh
+		day.CarbonGrams = day.WallWh / 1e3 * opts.CarbonGramsPerKWh
+
+		report.TotalWallWh += day.WallWh
+		report.TotalBatteryWh += day.BatteryWh
+		report.TotalCost += day.Cost
+		report.TotalCarbonGrams += day.CarbonGrams
+		report.Days = append(report.Days, *day)
+	}
+	sort.Slice(report.Days, func(i, j int) bool {
+		return report.Days[i].Date < report.Days[j].Date
+	})
+
+	return report
+}
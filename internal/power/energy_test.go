@@ -0,0 +1,81 @@
+package power
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func approxEqual(a, b float64) bool {
+	return math.Abs(a-b) < 1e-6
+}
+
+func TestComputeEnergy(t *testing.T) {
+	base := time.Date(2025, 1, 15, 22, 0, 0, 0, time.UTC)
+	snapshots := []Snapshot{
+		// On battery: 12V * -1A = 12W for 30 minutes -> 6 Wh from battery.
+		{Timestamp: base, VoltageMV: 12000, AmperageMA: -1000},
+		// On AC with system input power: 30W for 30 minutes -> 15 Wh from the wall,
+		// and the battery is charging so nothing drawn from it.
+		{Timestamp: base.Add(30 * time.Minute), VoltageMV: 12000, AmperageMA: 2000, ExternalConnected: true, SystemPowerMW: 30000},
+		// On AC without system power: charging power 12V * 2A = 24W for 2h30m,
+		// but the gap exceeds MaxGap so it is skipped.
+		{Timestamp: base.Add(time.Hour), VoltageMV: 12000, AmperageMA: 2000, ExternalConnected: true},
+		// Next day: charging 24W for 15 minutes -> 6 Wh from the wall.
+		{Timestamp: base.Add(3*time.Hour + 30*time.Minute), VoltageMV: 12000, AmperageMA: 2000, ExternalConnected: true},
+		{Timestamp: base.Add(3*time.Hour + 45*time.Minute), VoltageMV: 12000, AmperageMA: 2000, ExternalConnected: true},
+		// Snapshots without electrical readings (e.g. imported) are ignored.
+		{Timestamp: base.Add(3*time.Hour + 50*time.Minute), BatteryPct: 90},
+	}
+
+	report := ComputeEnergy(snapshots, EnergyOptions{
+		PricePerKWh:       0.30,
+		CarbonGramsPerKWh: 400,
+		MaxGap:            time.Hour,
+		Location:          time.UTC,
+	})
+
+	if report.Samples != 5 {
+		t.Errorf("Samples = %d, want 5", report.Samples)
+	}
+	if len(report.Days) != 2 {
+		t.Fatalf("expected 2 days, got %d: %+v", len(report.Days), report.Days)
+	}
+
+	day1 := report.Days[0]
+	if day1.Date != "2025-01-15" {
+		t.Errorf("day1 Date = %q, want 2025-01-15", day1.Date)
+	}
+	if !approxEqual(day1.BatteryWh, 6) {
+		t.Errorf("day1 BatteryWh = %f, want 6", day1.BatteryWh)
+	}
+	if !approxEqual(day1.WallWh, 15) {
+		t.Errorf("day1 WallWh = %f, want 15", day1.WallWh)
+	}
+
+	day2 := report.Days[1]
+	if day2.Date != "2025-01-16" {
+		t.Errorf("day2 Date = %q, want 2025-01-16", day2.Date)
+	}
+	if !approxEqual(day2.WallWh, 6) {
+		t.Errorf("day2 WallWh = %f, want 6", day2.WallWh)
+	}
+
+	if !approxEqual(report.TotalWallWh, 21) {
+		t.Errorf("TotalWallWh = %f, want 21", report.TotalWallWh)
+	}
+	// 21 Wh = 0.021 kWh.
+	if !approxEqual(report.TotalCost, 0.021*0.30) {
+		t.Errorf("TotalCost = %f, want %f", report.TotalCost, 0.021*0.30)
+	}
+	if !approxEqual(report.TotalCarbonGrams, 0.021*400) {
+		t.Errorf("TotalCarbonGrams = %f, want %f", report.TotalCarbonGrams, 0.021*400)
+	}
+}
+
+func TestComputeEnergyEmpty(t *testing.T) {
+	report := ComputeEnergy(nil, EnergyOptions{})
+	if len(report.Days) != 0 || report.Samples != 0 {
+		t.Errorf("expected empty report, got %+v", report)
+	}
+}
@@ -9,8 +9,9 @@ import (
 )
 
 const (
-	// MaxHistoryEntries is the maximum number of entries to keep in the history file.
-	MaxHistoryEntries = 500
+	// MaxHistoryAge is how long history entries are kept. At the recorder's
+	// 5m interval this holds several times the longest default window.
+	MaxHistoryAge = 30 * 24 * time.Hour
 
 	// DefaultHistoryCount is the default number of entries to show.
 	DefaultHistoryCount = 20
@@ -20,13 +21,18 @@ const (
 
 // Snapshot holds a point-in-time power measurement.
 type Snapshot struct {
-	Timestamp    time.Time `json:"timestamp"`
-	BatteryPct   int       `json:"battery_pct"`
-	IsCharging   bool      `json:"is_charging"`
-	CycleCount   int       `json:"cycle_count"`
-	MaxCapacity  int       `json:"max_capacity_mah"`
-	Temperature  float64   `json:"temperature_celsius"`
-	ThermalLevel string    `json:"thermal_level"`
+	Timestamp         time.Time `json:"timestamp"`
+	BatteryPct        int       `json:"battery_pct"`
+	IsCharging        bool      `json:"is_charging"`
+	ExternalConnected bool      `json:"external_connected,omitempty"`
+	CycleCount        int       `json:"cycle_count"`
+	MaxCapacity       int       `json:"max_capacity_mah"`
+	Temperature       float64   `json:"temperature_celsius"`
+	ThermalLevel      string    `json:"thermal_level"`
+	VoltageMV         int       `json:"voltage_mv,omitempty"`
+	AmperageMA        int       `json:"amperage_ma,omitempty"`
+	SystemPowerMW     int       `json:"system_power_in_mw,omitempty"`
+	Source            string    `json:"source,omitempty"`
 }
 
 // TakeSnapshot captures the current power state as a Snapshot.
@@ -42,13 +48,17 @@ func TakeSnapshot() (*Snapshot, error) {
 	}
 
 	return &Snapshot{
-		Timestamp:    time.Now().UTC(),
-		BatteryPct:   status.Percent,
-		IsCharging:   status.IsCharging,
-		CycleCount:   status.CycleCount,
-		MaxCapacity:  status.MaxCapacity,
-		Temperature:  status.Temperature,
-		ThermalLevel: thermal.PressureLevel,
+		Timestamp:         time.Now().UTC(),
+		BatteryPct:        status.Percent,
+		IsCharging:        status.IsCharging,
+		ExternalConnected: status.ExternalConnected,
+		CycleCount:        status.CycleCount,
+		MaxCapacity:       status.MaxCapacity,
+		Temperature:       status.Temperature,
+		ThermalLevel:      thermal.PressureLevel,
+		VoltageMV:         status.VoltageMV,
+		AmperageMA:        status.AmperageMA,
+		SystemPowerMW:     status.SystemPowerMW,
 	}, nil
 }
 
@@ -84,12 +94,22 @@ func LoadHistory() ([]Snapshot, error) {
 	return snapshots, nil
 }
 
-// SaveHistory writes snapshots to the history file, keeping at most MaxHistoryEntries.
-func SaveHistory(snapshots []Snapshot) error {
-	// Trim to max entries.
-	if len(snapshots) > MaxHistoryEntries {
-		snapshots = snapshots[len(snapshots)-MaxHistoryEntries:]
+// TrimHistory returns the snapshots newer than MaxHistoryAge before now.
+func TrimHistory(snapshots []Snapshot, now time.Time) []Snapshot {
+	cutoff := now.Add(-MaxHistoryAge)
+	var kept []Snapshot
+	for _, s := range snapshots {
+		if s.Timestamp.After(cutoff) {
+			kept = append(kept, s)
+		}
 	}
+	return kept
+}
+
+// SaveHistory writes snapshots to the history file, dropping entries older
+// than MaxHistoryAge.
+func SaveHistory(snapshots []Snapshot) error {
+	snapshots = TrimHistory(snapshots, time.Now())
 
 	path, err := historyPath()
 	if err != nil {
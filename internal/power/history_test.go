@@ -135,49 +135,25 @@ func TestSaveAndLoadHistory(t *testing.T) {
 	}
 }
 
-func TestSaveHistoryTrimming(t *testing.T) {
-	tmpDir := t.TempDir()
-	histPath := filepath.Join(tmpDir, "power-history.json")
+func TestTrimHistory(t *testing.T) {
+	now := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
 
-	// Create more than MaxHistoryEntries snapshots.
+	// A 5m recording interval over twice the retention window.
 	var snapshots []Snapshot
-	for i := 0; i < MaxHistoryEntries+50; i++ {
-		snapshots = append(snapshots, Snapshot{
-			Timestamp:  time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC).Add(time.Duration(i) * time.Minute),
-			BatteryPct: i % 100,
-		})
+	for ts := now.Add(-2 * MaxHistoryAge); !ts.After(now); ts = ts.Add(5 * time.Minute) {
+		snapshots = append(snapshots, Snapshot{Timestamp: ts})
 	}
 
-	// Trim like SaveHistory does.
-	if len(snapshots) > MaxHistoryEntries {
-		snapshots = snapshots[len(snapshots)-MaxHistoryEntries:]
-	}
-
-	data, err := json.MarshalIndent(snapshots, "", "  ")
-	if err != nil {
-		t.Fatalf("failed to marshal: %v", err)
+	kept := TrimHistory(snapshots, now)
+	if want := int(MaxHistoryAge / (5 * time.Minute)); len(kept) != want {
+		t.Errorf("kept %d entries, want %d", len(kept), want)
 	}
-	if err := os.WriteFile(histPath, data, 0o644); err != nil {
-		t.Fatalf("failed to write: %v", err)
+	if !kept[0].Timestamp.After(now.Add(-MaxHistoryAge)) {
+		t.Errorf("oldest kept entry %v is outside the retention window", kept[0].Timestamp)
 	}
-
-	readData, err := os.ReadFile(histPath)
-	if err != nil {
-		t.Fatalf("failed to read: %v", err)
-	}
-
-	var loaded []Snapshot
-	if err := json.Unmarshal(readData, &loaded); err != nil {
-		t.Fatalf("failed to unmarshal: %v", err)
-	}
-
-	if len(loaded) != MaxHistoryEntries {
-		t.Errorf("expected %d entries after trimming, got %d", MaxHistoryEntries, len(loaded))
-	}
-
-	// The first entry should be the 50th one (0-indexed), since we trimmed the first 50.
-	if loaded[0].BatteryPct != 50 {
-		t.Errorf("first entry BatteryPct = %d, want 50", loaded[0].BatteryPct)
+	// The default energy window is fully covered.
+	if kept[0].Timestamp.After(now.Add(-7 * 24 * time.Hour)) {
+		t.Errorf("oldest kept entry %v does not cover 7 days", kept[0].Timestamp)
 	}
 }
 
@@ -0,0 +1,142 @@
+package power
+
+import (
+	"fmt"
+	"os/exec"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SourcePmsetLog marks snapshots imported from the pmset log rather than
+// recorded live. Imported snapshots only carry charge and adapter state.
+const SourcePmsetLog = "pmset-log"
+
+// pmsetLogRe matches pmset log lines that report battery charge, e.g.:
+//
+//	2025-01-15 08:12:51 -0800 Wake   Wake from Deep Idle [CDNVA] : due to EC.LidOpen/Lid Open Using BATT (Charge:76%) 2 secs
+var pmsetLogRe = regexp.MustCompile(`^(\d{4}-\d{2}-\d{2} \d{2}:\d{2}:\d{2} [+-]\d{4})\s.*?Using (AC|Batt|BATT)\s*\(Charge:\s*(\d+)%?\)`)
+
+// ImportPmsetLog parses `pmset -g log` and merges the battery readings into
+// the history file. It returns the number of imported snapshots kept, not
+// counting those older than MaxHistoryAge.
+func ImportPmsetLog() (int, error) {
+	out, err := exec.Command("pmset", "-g", "log").Output()
+	if err != nil {
+		return 0, fmt.Errorf("failed to read pmset log: %w", err)
+	}
+
+	imported := ParsePmsetLog(string(out))
+
+	existing, err := LoadHistory()
+	if err != nil {
+		return 0, err
+	}
+
+	merged, kept := importSnapshots(existing, imported, time.Now())
+	if kept == 0 {
+		return 0, nil
+	}
+
+	if err := SaveHistory(merged); err != nil {
+		return 0, err
+	}
+	return kept, nil
+}
+
+// importSnapshots merges imported into existing and trims the result as
+// SaveHistory will. It returns the trimmed history and how many imported
+// snapshots survived the trim.
+func importSnapshots(existing, imported []Snapshot, now time.Time) ([]Snapshot, int) {
+	merged, added := MergeSnapshots(existing, imported)
+	if added == 0 {
+		return merged, 0
+	}
+
+	old := make(map[int64]bool, len(existing))
+	for _, s := range existing {
+		old[s.Timestamp.Unix()] = true
+	}
+
+	merged = TrimHistory(merged, now)
+	kept := 0
+	for _, s := range merged {
+		if !old[s.Timestamp.Unix()] {
+			kept++
+		}
+	}
+	return merged, kept
+}
+
+// ParsePmsetLog extracts battery snapshots from `pmset -g log` output.
+// Consecutive lines with the same charge and adapter state are collapsed
+// into the first one.
+func ParsePmsetLog(output string) []Snapshot {
+	var snapshots []Snapshot
+
+	for _, line := range strings.Split(output, "\n") {
+		m := pmsetLogRe.FindStringSubmatch(strings.TrimSpace(line))
+		if len(m) < 4 {
+			continue
+		}
+
+		ts, err := time.Parse("2006-01-02 15:04:05 -0700", m[1])
+		if err != nil {
+			continue
+		}
+		pct, err := strconv.Atoi(m[3])
+		if err != nil {
+			continue
+		}
+		onAC := m[2] == "AC"
+
+		if n := len(snapshots); n > 0 {
+			prev := snapshots[n-1]
+			if prev.BatteryPct == pct && prev.ExternalConnected == onAC {
+				continue
+			}
+		}
+
+		snapshots = append(snapshots, Snapshot{
+			Timestamp:         ts.UTC(),
+			BatteryPct:        pct,
+			ExternalConnected: onAC,
+			// The log doesn't say whether the battery is charging; assume it
+			// is whenever the adapter is attached and the battery isn't full.
+			IsCharging: onAC && pct < 100,
+			Source:     SourcePmsetLog,
+		})
+	}
+
+	return snapshots
+}
+
+// MergeSnapshots adds imported snapshots to existing ones, skipping any whose
+// timestamp (to the second) is already present, and returns the merged list
+// sorted by time along with the number of snapshots added.
+func MergeSnapshots(existing, imported []Snapshot) ([]Snapshot, int) {
+	seen := make(map[int64]bool, len(existing))
+	for _, s := range existing {
+		seen[s.Timestamp.Unix()] = true
+	}
+
+	merged := append([]Snapshot(nil), existing...)
+	added := 0
+	for _, s := range imported {
+		key := s.Timestamp.Unix()
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		merged = append(merged, s)
+		added++
+	}
+
+	sort.SliceStable(merged, func(i, j int) bool {
+		return merged[i].Timestamp.Before(merged[j].Timestamp)
+	})
+
+	return merged, added
+}
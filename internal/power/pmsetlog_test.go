@@ -0,0 +1,117 @@
+package power
+
+import (
+	"testing"
+	"time"
+)
+
+const samplePmsetLog = `Time stamp                Domain              	Message                                                                  	Duration  	Delay
+==========                ======              	=======                                                                  	========  	=====
+2025-01-14 22:10:05 -0800 Sleep               	Entering Sleep state due to 'Clamshell Sleep':TCPKeepAlive=active Using Batt (Charge:76%)	8 secs
+2025-01-14 23:40:12 -0800 DarkWake            	DarkWake from Deep Idle [CDN] : due to SMC.OutboxNotEmpty/ Using BATT (Charge:75%)	45 secs
+2025-01-14 23:40:57 -0800 Sleep               	Entering Sleep state due to 'Maintenance Sleep':TCPKeepAlive=active Using Batt (Charge:75%)	7 hours
+2025-01-15 07:02:33 -0800 Wake                	Wake from Deep Idle [CDNVA] : due to EC.LidOpen/Lid Open Using BATT (Charge:71%)	30 secs
+2025-01-15 07:03:10 -0800 Assertions          	PID 312(coreaudiod) Created PreventUserIdleSystemSleep "com.apple.audio.context"
+2025-01-15 08:15:00 -0800 Summary- [System: DeclUser kDisp] Using AC(Charge: 64)
+2025-01-15 08:45:00 -0800 Charge              	Using AC (Charge:100%)
+`
+
+func TestParsePmsetLog(t *testing.T) {
+	snaps := ParsePmsetLog(samplePmsetLog)
+
+	// 75% appears twice in a row on battery and collapses into one snapshot.
+	if len(snaps) != 5 {
+		t.Fatalf("expected 5 snapshots, got %d: %+v", len(snaps), snaps)
+	}
+
+	first := snaps[0]
+	wantTS := time.Date(2025, 1, 15, 6, 10, 5, 0, time.UTC)
+	if !first.Timestamp.Equal(wantTS) {
+		t.Errorf("first Timestamp = %v, want %v", first.Timestamp, wantTS)
+	}
+	if first.BatteryPct != 76 {
+		t.Errorf("first BatteryPct = %d, want 76", first.BatteryPct)
+	}
+	if first.ExternalConnected || first.IsCharging {
+		t.Errorf("first snapshot should be on battery, got %+v", first)
+	}
+	if first.Source != SourcePmsetLog {
+		t.Errorf("Source = %q, want %q", first.Source, SourcePmsetLog)
+	}
+
+	summary := snaps[3]
+	if summary.BatteryPct != 64 || !summary.ExternalConnected || !summary.IsCharging {
+		t.Errorf("summary line snapshot = %+v, want 64%% on AC charging", summary)
+	}
+
+	full := snaps[4]
+	if full.BatteryPct != 100 || !full.ExternalConnected || full.IsCharging {
+		t.Errorf("full charge snapshot = %+v, want 100%% on AC not charging", full)
+	}
+}
+
+func TestParsePmsetLogEmpty(t *testing.T) {
+	if snaps := ParsePmsetLog(""); len(snaps) != 0 {
+		t.Errorf("expected no snapshots, got %d", len(snaps))
+	}
+}
+
+func TestMergeSnapshots(t *testing.T) {
+	base := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	existing := []Snapshot{
+		{Timestamp: base, BatteryPct: 80},
+		{Timestamp: base.Add(2 * time.Hour), BatteryPct: 60},
+	}
+	imported := []Snapshot{
+		{Timestamp: base.Add(-time.Hour), BatteryPct: 90, Source: SourcePmsetLog},
+		{Timestamp: base.Add(500 * time.Millisecond), BatteryPct: 80, Source: SourcePmsetLog},
+		{Timestamp: base.Add(time.Hour), BatteryPct: 70, Source: SourcePmsetLog},
+	}
+
+	merged, added := MergeSnapshots(existing, imported)
+	if added != 2 {
+		t.Errorf("added = %d, want 2", added)
+	}
+	if len(merged) != 4 {
+		t.Fatalf("len(merged) = %d, want 4", len(merged))
+	}
+
+	wantPcts := []int{90, 80, 70, 60}
+	for i, want := range wantPcts {
+		if merged[i].BatteryPct != want {
+			t.Errorf("merged[%d].BatteryPct = %d, want %d", i, merged[i].BatteryPct, want)
+		}
+	}
+
+	// Importing the same entries again adds nothing.
+	_, added = MergeSnapshots(merged, imported)
+	if added != 0 {
+		t.Errorf("re-import added = %d, want 0", added)
+	}
+}
+
+func TestImportSnapshotsCountsOnlyKept(t *testing.T) {
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	existing := []Snapshot{
+		{Timestamp: now.Add(-time.Hour), BatteryPct: 80},
+	}
+	imported := []Snapshot{
+		{Timestamp: now.Add(-MaxHistoryAge - time.Hour), BatteryPct: 99, Source: SourcePmsetLog},
+		{Timestamp: now.Add(-2 * time.Hour), BatteryPct: 85, Source: SourcePmsetLog},
+		{Timestamp: now.Add(-time.Hour), BatteryPct: 80, Source: SourcePmsetLog},
+	}
+
+	merged, kept := importSnapshots(existing, imported, now)
+	if kept != 1 {
+		t.Errorf("kept = %d, want 1", kept)
+	}
+	if len(merged) != 2 {
+		t.Errorf("len(merged) = %d, want 2", len(merged))
+	}
+
+	// Entries that are all past the retention window add nothing.
+	_, kept = importSnapshots(nil, imported[:1], now)
+	if kept != 0 {
+		t.Errorf("kept = %d for expired entries, want 0", kept)
+	}
+}
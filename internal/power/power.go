@@ -8,16 +8,23 @@ import (
 	"strings"
 )
 
-// Status holds battery status information.
+// Status holds battery status information. On Macs without an internal
+// battery, HasBattery is false and Sources lists any UPS or external source.
 type Status struct {
-	Percent           int     `json:"percent"`
-	IsCharging        bool    `json:"is_charging"`
-	ExternalConnected bool    `json:"external_connected"`
-	TimeRemaining     string  `json:"time_remaining"`
-	CycleCount        int     `json:"cycle_count"`
-	Temperature       float64 `json:"temperature_celsius"`
-	CurrentCapacity   int     `json:"current_capacity_mah"`
-	MaxCapacity       int     `json:"max_capacity_mah"`
+	HasBattery        bool          `json:"has_battery"`
+	PowerSource       string        `json:"power_source,omitempty"`
+	Percent           int           `json:"percent"`
+	IsCharging        bool          `json:"is_charging"`
+	ExternalConnected bool          `json:"external_connected"`
+	TimeRemaining     string        `json:"time_remaining"`
+	CycleCount        int           `json:"cycle_count"`
+	Temperature       float64       `json:"temperature_celsius"`
+	CurrentCapacity   int           `json:"current_capacity_mah"`
+	MaxCapacity       int           `json:"max_capacity_mah"`
+	VoltageMV         int           `json:"voltage_mv"`
+	AmperageMA        int           `json:"amperage_ma"`
+	SystemPowerMW     int           `json:"system_power_in_mw,omitempty"`
+	Sources           []PowerSource `json:"sources,omitempty"`
 }
 
 // Health holds battery health information.
@@ -68,16 +75,22 @@ func GetStatus() (*Status, error) {
 	s.CycleCount = extractInt(raw, `"CycleCount"\s*=\s*(\d+)`)
 	s.IsCharging = extractBool(raw, `"IsCharging"\s*=\s*(Yes|No)`)
 	s.ExternalConnected = extractBool(raw, `"ExternalConnected"\s*=\s*(Yes|No)`)
+	s.VoltageMV = extractInt(raw, `"Voltage"\s*=\s*(\d+)`)
+	s.AmperageMA = extractSignedInt(raw, `"Amperage"\s*=\s*(-?\d+)`)
+	s.SystemPowerMW = extractInt(raw, `"SystemPowerIn"\s*=\s*(\d+)`)
 
 	tempRaw := extractInt(raw, `"Temperature"\s*=\s*(\d+)`)
 	if tempRaw > 0 {
 		s.Temperature = float64(tempRaw) / 100.0
 	}
 
-	// Get time remaining from pmset.
-	pmOut, err := exec.Command("pmset", "-g", "batt").Output()
+	s.HasBattery = strings.Contains(raw, `"CurrentCapacity"`)
+
+	// Get time remaining and any other power sources from pmset.
+	pmOut, err := exec.Command("pmset", "-g", "ps").Output()
 	if err == nil {
 		s.TimeRemaining = extractTimeRemaining(string(pmOut))
+		applySources(s, parsePowerSources(string(pmOut)))
 	}
 
 	return s, nil
@@ -172,6 +185,35 @@ func GetEnergyHogs(n int) ([]EnergyHog, error) {
 	return parseEnergyHogs(string(out), n), nil
 }
 
+// applySources fills in the active power source and, on Macs without an
+// internal battery, the charge and state of the first UPS or external source.
+func applySources(s *Status, info *SourceInfo) {
+	s.PowerSource = info.DrawingFrom
+
+	var external []PowerSource
+	for _, src := range info.Sources {
+		if src.Type == SourceTypeInternalBattery {
+			s.TimeRemaining = src.TimeRemaining
+			continue
+		}
+		external = append(external, src)
+	}
+	s.Sources = external
+
+	if s.HasBattery {
+		return
+	}
+
+	s.ExternalConnected = info.DrawingFrom == "AC Power"
+	if len(external) > 0 {
+		s.Percent = external[0].Percent
+		s.TimeRemaining = external[0].TimeRemaining
+		s.IsCharging = strings.Contains(external[0].State, "charging") &&
+			!strings.Contains(external[0].State, "not charging") &&
+			!strings.Contains(external[0].State, "discharging")
+	}
+}
+
 func extractInt(s, pattern string) int {
 	re := regexp.MustCompile(pattern)
 	m := re.FindStringSubmatch(s)
@@ -182,6 +224,23 @@ func extractInt(s, pattern string) int {
 	return v
 }
 
+// extractSignedInt is like extractInt but handles ioreg printing negative
+// values (such as a discharging battery's amperage) as unsigned 64-bit.
+func extractSignedInt(s, pattern string) int {
+	re := regexp.MustCompile(pattern)
+	m := re.FindStringSubmatch(s)
+	if len(m) < 2 {
+		return 0
+	}
+	if v, err := strconv.ParseInt(m[1], 10, 64); err == nil {
+		return int(v)
+	}
+	if v, err := strconv.ParseUint(m[1], 10, 64); err == nil {
+		return int(int64(v))
+	}
+	return 0
+}
+
 func extractBool(s, pattern string) bool {
 	re := regexp.MustCompile(pattern)
 	m := re.FindStringSubmatch(s)
@@ -33,6 +33,28 @@ func TestExtractInt(t *testing.T) {
 	}
 }
 
+func TestExtractSignedInt(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  int
+	}{
+		{name: "positive", input: `"Amperage" = 1520`, want: 1520},
+		{name: "negative", input: `"Amperage" = -812`, want: -812},
+		{name: "unsigned wraparound", input: `"Amperage" = 18446744073709550804`, want: -812},
+		{name: "no match", input: `"InstantAmperage" = 5`, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := extractSignedInt(tt.input, `"Amperage"\s*=\s*(-?\d+)`)
+			if got != tt.want {
+				t.Errorf("extractSignedInt() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestExtractBool(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -0,0 +1,99 @@
+package power
+
+import (
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Power source type constants.
+const (
+	SourceTypeInternalBattery = "internal_battery"
+	SourceTypeUPS             = "ups"
+	SourceTypeExternalBattery = "external_battery"
+)
+
+// PowerSource holds a single power source reported by pmset, such as the
+// internal battery or a UPS connected over USB.
+type PowerSource struct {
+	Name          string `json:"name"`
+	ID            int    `json:"id,omitempty"`
+	Type          string `json:"type"`
+	Percent       int    `json:"percent"`
+	State         string `json:"state"`
+	TimeRemaining string `json:"time_remaining"`
+	Present       bool   `json:"present"`
+}
+
+// SourceInfo holds the active power source and all sources pmset knows about.
+type SourceInfo struct {
+	DrawingFrom string        `json:"drawing_from"`
+	Sources     []PowerSource `json:"sources"`
+}
+
+// GetSources returns all power sources listed by `pmset -g ps`.
+func GetSources() (*SourceInfo, error) {
+	out, err := exec.Command("pmset", "-g", "ps").Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read power sources: %w", err)
+	}
+
+	return parsePowerSources(string(out)), nil
+}
+
+var (
+	drawingFromRe = regexp.MustCompile(`Now drawing from '([^']+)'`)
+
+	// sourceLineRe matches pmset source lines like:
+	//  -InternalBattery-0 (id=4391011)	85%; discharging; 3:45 remaining present: true
+	sourceLineRe = regexp.MustCompile(`^\s*-(.+?)\s+\(id=(\d+)\)\s+(\d+)%;\s*([^;]+);?\s*(.*?)\s*(?:present:\s*(true|false))?\s*$`)
+)
+
+func parsePowerSources(output string) *SourceInfo {
+	info := &SourceInfo{}
+
+	if m := drawingFromRe.FindStringSubmatch(output); len(m) > 1 {
+		info.DrawingFrom = m[1]
+	}
+
+	for _, line := range strings.Split(output, "\n") {
+		m := sourceLineRe.FindStringSubmatch(line)
+		if len(m) < 7 {
+			continue
+		}
+
+		id, _ := strconv.Atoi(m[2])
+		pct, _ := strconv.Atoi(m[3])
+		state := strings.TrimSpace(m[4])
+
+		info.Sources = append(info.Sources, PowerSource{
+			Name:          m[1],
+			ID:            id,
+			Type:          classifySource(m[1]),
+			Percent:       pct,
+			State:         state,
+			TimeRemaining: extractTimeRemaining(state + "; " + m[5]),
+			Present:       m[6] != "false",
+		})
+	}
+
+	return info
+}
+
+// classifySource guesses a source's type from its name. pmset lists HID
+// power devices by product name, and those are almost always UPSes.
+func classifySource(name string) string {
+	lower := strings.ToLower(name)
+	switch {
+	case strings.HasPrefix(lower, "internalbattery"):
+		return SourceTypeInternalBattery
+	case strings.Contains(lower, "ups"):
+		return SourceTypeUPS
+	case strings.Contains(lower, "battery"):
+		return SourceTypeExternalBattery
+	default:
+		return SourceTypeUPS
+	}
+}
@@ -0,0 +1,132 @@
+package power
+
+import "testing"
+
+func TestParsePowerSources(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       string
+		drawingFrom string
+		want        []PowerSource
+	}{
+		{
+			name: "laptop on battery",
+			input: `Now drawing from 'Battery Power'
+ -InternalBattery-0 (id=4391011)	85%; discharging; 3:45 remaining present: true
+`,
+			drawingFrom: "Battery Power",
+			want: []PowerSource{
+				{Name: "InternalBattery-0", ID: 4391011, Type: SourceTypeInternalBattery, Percent: 85, State: "discharging", TimeRemaining: "3:45", Present: true},
+			},
+		},
+		{
+			name: "desktop on UPS",
+			input: `Now drawing from 'UPS Power'
+ -CP1500PFCLCD (id=5678)	92%; discharging; 0:41 remaining present: true
+`,
+			drawingFrom: "UPS Power",
+			want: []PowerSource{
+				{Name: "CP1500PFCLCD", ID: 5678, Type: SourceTypeUPS, Percent: 92, State: "discharging", TimeRemaining: "0:41", Present: true},
+			},
+		},
+		{
+			name: "laptop with UPS",
+			input: `Now drawing from 'AC Power'
+ -InternalBattery-0 (id=4391011)	100%; charged; 0:00 remaining present: true
+ -Back-UPS ES 700 (id=7890)	100%; charged; 0:00 remaining present: true
+`,
+			drawingFrom: "AC Power",
+			want: []PowerSource{
+				{Name: "InternalBattery-0", ID: 4391011, Type: SourceTypeInternalBattery, Percent: 100, State: "charged", TimeRemaining: "fully charged", Present: true},
+				{Name: "Back-UPS ES 700", ID: 7890, Type: SourceTypeUPS, Percent: 100, State: "charged", TimeRemaining: "fully charged", Present: true},
+			},
+		},
+		{
+			name: "desktop without sources",
+			input: `Now drawing from 'AC Power'
+`,
+			drawingFrom: "AC Power",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := parsePowerSources(tt.input)
+			if got.DrawingFrom != tt.drawingFrom {
+				t.Errorf("DrawingFrom = %q, want %q", got.DrawingFrom, tt.drawingFrom)
+			}
+			if len(got.Sources) != len(tt.want) {
+				t.Fatalf("got %d sources, want %d: %+v", len(got.Sources), len(tt.want), got.Sources)
+			}
+			for i, want := range tt.want {
+				if got.Sources[i] != want {
+					t.Errorf("Sources[%d] = %+v, want %+v", i, got.Sources[i], want)
+				}
+			}
+		})
+	}
+}
+
+func TestApplySourcesDesktopOnUPS(t *testing.T) {
+	s := &Status{HasBattery: false}
+	applySources(s, &SourceInfo{
+		DrawingFrom: "AC Power",
+		Sources: []PowerSource{
+			{Name: "CP1500PFCLCD", Type: SourceTypeUPS, Percent: 97, State: "charging", TimeRemaining: "unknown"},
+		},
+	})
+
+	if s.PowerSource != "AC Power" {
+		t.Errorf("PowerSource = %q, want %q", s.PowerSource, "AC Power")
+	}
+	if !s.ExternalConnected {
+		t.Error("expected ExternalConnected on AC power")
+	}
+	if s.Percent != 97 {
+		t.Errorf("Percent = %d, want 97", s.Percent)
+	}
+	if !s.IsCharging {
+		t.Error("expected IsCharging from UPS state")
+	}
+	if len(s.Sources) != 1 {
+		t.Errorf("len(Sources) = %d, want 1", len(s.Sources))
+	}
+}
+
+func TestApplySourcesLaptopKeepsBatteryReadings(t *testing.T) {
+	s := &Status{HasBattery: true, Percent: 64, ExternalConnected: true}
+	applySources(s, &SourceInfo{
+		DrawingFrom: "AC Power",
+		Sources: []PowerSource{
+			{Name: "InternalBattery-0", Type: SourceTypeInternalBattery, Percent: 64, State: "charging", TimeRemaining: "1:10"},
+		},
+	})
+
+	if s.Percent != 64 {
+		t.Errorf("Percent = %d, want 64", s.Percent)
+	}
+	if s.TimeRemaining != "1:10" {
+		t.Errorf("TimeRemaining = %q, want %q", s.TimeRemaining, "1:10")
+	}
+	if len(s.Sources) != 0 {
+		t.Errorf("expected no external sources, got %+v", s.Sources)
+	}
+}
+
+func TestClassifySource(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "InternalBattery-0", want: SourceTypeInternalBattery},
+		{name: "Back-UPS ES 700", want: SourceTypeUPS},
+		{name: "CP1500PFCLCD", want: SourceTypeUPS},
+		{name: "Anker Battery Pack", want: SourceTypeExternalBattery},
+	}
+
+	for _, tt := range tests {
+		if got := classifySource(tt.name); got != tt.want {
+			t.Errorf("classifySource(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
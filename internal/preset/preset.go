@@ -5,6 +5,7 @@ import (
 	"strings"
 
 	"github.com/lu-zhengda/macctl/internal/audio"
+	"github.com/lu-zhengda/macctl/internal/disk"
 	"github.com/lu-zhengda/macctl/internal/display"
 	"github.com/lu-zhengda/macctl/internal/focus"
 	"github.com/lu-zhengda/macctl/internal/power"
@@ -77,6 +78,13 @@ func BuiltinPresets() []Preset {
 				{Domain: "power", Command: "hogs"},
 			},
 		},
+		{
+			Name:        "undock",
+			Description: "Eject all external disks",
+			Actions: []Action{
+				{Domain: "disk", Command: "eject", Args: []string{disk.AllExternal}},
+			},
+		},
 	}
 }
 
@@ -125,6 +133,8 @@ func executeAction(a Action) Result {
 		err = executeAudioAction(a)
 	case "power":
 		return executePowerAction(a)
+	case "disk":
+		err = executeDiskAction(a)
 	default:
 		return Result{Action: a, Success: false, Message: fmt.Sprintf("unknown domain: %s", a.Domain)}
 	}
@@ -194,6 +204,28 @@ func executeAudioAction(a Action) error {
 	}
 }
 
+// executeDiskAction runs eject, mount or unmount on the volume or disk in
+// the first argument; a second argument of "force" forces the unmount.
+func executeDiskAction(a Action) error {
+	if len(a.Args) == 0 {
+		return fmt.Errorf("disk %s requires a volume or disk name", a.Command)
+	}
+	force := len(a.Args) > 1 && a.Args[1] == "force"
+
+	var err error
+	switch a.Command {
+	case "eject":
+		_, err = disk.Eject(a.Args[0], force)
+	case "mount":
+		_, err = disk.MountVolume(a.Args[0])
+	case "unmount":
+		_, err = disk.UnmountVolume(a.Args[0], force)
+	default:
+		return fmt.Errorf("unknown disk command: %s", a.Command)
+	}
+	return err
+}
+
 func executePowerAction(a Action) Result {
 	switch a.Command {
 	case "hogs":
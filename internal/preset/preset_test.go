@@ -22,7 +22,7 @@ func TestBuiltinPresets(t *testing.T) {
 		names[p.Name] = true
 	}
 
-	expected := []string{"deep-work", "meeting", "present", "chill", "battery-saver"}
+	expected := []string{"deep-work", "meeting", "present", "chill", "battery-saver", "undock"}
 	for _, name := range expected {
 		if !names[name] {
 			t.Errorf("missing expected preset: %s", name)
@@ -0,0 +1,206 @@
+package recorder
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"strings"
+	"time"
+
+	"github.com/lu-zhengda/macctl/internal/disk"
+	"github.com/lu-zhengda/macctl/internal/events"
+	"github.com/lu-zhengda/macctl/internal/power"
+)
+
+// Domain constants for the histories the recorder can keep fresh.
+const (
+	DomainPower   = "power"
+	DomainDisk    = "disk"
+	DomainThermal = "thermal"
+	DomainEvents  = "events"
+)
+
+const (
+	// DefaultInterval is the default time between recording rounds, in the
+	// m/h/d form the --every flag takes.
+	DefaultInterval = "5m"
+
+	// DefaultMaxBackoff caps how long a failing domain is skipped for.
+	DefaultMaxBackoff = time.Hour
+)
+
+// DefaultDomains is the domain list used when none is given.
+var DefaultDomains = []string{DomainPower, DomainDisk, DomainThermal, DomainEvents}
+
+var knownDomains = []string{DomainPower, DomainDisk, DomainThermal, DomainEvents}
+
+// Task records a single domain's snapshot.
+type Task struct {
+	Name   string
+	Record func() error
+}
+
+// Config controls the recording loop.
+type Config struct {
+	Interval   time.Duration
+	Jitter     time.Duration
+	MaxBackoff time.Duration
+	Tasks      []Task
+}
+
+// Result holds the outcome of running a task once.
+type Result struct {
+	Timestamp time.Time `json:"timestamp"`
+	Domain    string    `json:"domain"`
+	Success   bool      `json:"success"`
+	Error     string    `json:"error,omitempty"`
+	Failures  int       `json:"consecutive_failures,omitempty"`
+	NextRun   time.Time `json:"next_run"`
+}
+
+// ParseDomains parses a comma-separated domain list like "power,disk".
+func ParseDomains(s string) ([]string, error) {
+	if strings.TrimSpace(s) == "" {
+		return DefaultDomains, nil
+	}
+
+	seen := make(map[string]bool)
+	var domains []string
+	for _, d := range strings.Split(s, ",") {
+		d = strings.ToLower(strings.TrimSpace(d))
+		if d == "" {
+			continue
+		}
+		if !isKnownDomain(d) {
+			return nil, fmt.Errorf("unknown domain %q (use %s)", d, strings.Join(knownDomains, ", "))
+		}
+		if !seen[d] {
+			seen[d] = true
+			domains = append(domains, d)
+		}
+	}
+
+	if len(domains) == 0 {
+		return nil, fmt.Errorf("no domains given")
+	}
+	return domains, nil
+}
+
+// Tasks returns the recording tasks for the given domains. Thermal state is
+// stored in the power snapshot, so "power" and "thermal" share one task.
+func Tasks(domains []string) []Task {
+	var tasks []Task
+	havePower := false
+	for _, d := range domains {
+		switch d {
+		case DomainPower, DomainThermal:
+			if havePower {
+				continue
+			}
+			havePower = true
+			tasks = append(tasks, Task{Name: DomainPower, Record: func() error {
+				_, err := power.RecordSnapshot()
+				return err
+			}})
+		case DomainDisk:
+			tasks = append(tasks, Task{Name: DomainDisk, Record: func() error {
+				_, err := disk.RecordSnapshot(disk.DefaultDevice)
+				return err
+			}})
+		case DomainEvents:
+			tasks = append(tasks, Task{Name: DomainEvents, Record: func() error {
+				_, err := events.UpdateArchive()
+				return err
+			}})
+		}
+	}
+	return tasks
+}
+
+// Run records every task once per interval until ctx is cancelled. Each round
+// is delayed by a random jitter, and a task that keeps failing is skipped with
+// exponential backoff. onResult, if non-nil, is called after every task run.
+func Run(ctx context.Context, cfg Config, onResult func(Result)) error {
+	if cfg.Interval <= 0 {
+		return fmt.Errorf("interval must be positive")
+	}
+	if len(cfg.Tasks) == 0 {
+		return fmt.Errorf("no tasks to record")
+	}
+	if cfg.MaxBackoff <= 0 {
+		cfg.MaxBackoff = DefaultMaxBackoff
+	}
+
+	failures := make([]int, len(cfg.Tasks))
+	nextRun := make([]time.Time, len(cfg.Tasks))
+
+	for {
+		now := time.Now().UTC()
+		for i, t := range cfg.Tasks {
+			if ctx.Err() != nil {
+				return nil
+			}
+			if now.Before(nextRun[i]) {
+				continue
+			}
+
+			err := t.Record()
+			res := Result{Timestamp: now, Domain: t.Name, Success: err == nil}
+			if err != nil {
+				failures[i]++
+				res.Error = err.Error()
+				res.Failures = failures[i]
+				nextRun[i] = now.Add(Backoff(cfg.Interval, failures[i], cfg.MaxBackoff))
+			} else {
+				failures[i] = 0
+				nextRun[i] = now.Add(cfg.Interval)
+			}
+			res.NextRun = nextRun[i]
+
+			if onResult != nil {
+				onResult(res)
+			}
+		}
+
+		timer := time.NewTimer(Jittered(cfg.Interval, cfg.Jitter))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil
+		case <-timer.C:
+		}
+	}
+}
+
+// Backoff returns the delay before retrying a task that has failed the given
+// number of consecutive times: the interval doubled per failure, capped at max.
+func Backoff(interval time.Duration, failures int, max time.Duration) time.Duration {
+	if failures <= 1 {
+		return interval
+	}
+	d := interval
+	for i := 1; i < failures; i++ {
+		d *= 2
+		if d >= max {
+			return max
+		}
+	}
+	return d
+}
+
+// Jittered returns d plus a random duration in [0, jitter).
+func Jittered(d, jitter time.Duration) time.Duration {
+	if jitter <= 0 {
+		return d
+	}
+	return d + rand.N(jitter)
+}
+
+func isKnownDomain(d string) bool {
+	for _, k := range knownDomains {
+		if k == d {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,178 @@
+package recorder
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParseDomains(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    []string
+		wantErr bool
+	}{
+		{name: "empty uses defaults", input: "", want: DefaultDomains},
+		{name: "single", input: "power", want: []string{"power"}},
+		{name: "multiple", input: "power,disk", want: []string{"power", "disk"}},
+		{name: "spaces and case", input: " Disk , THERMAL ", want: []string{"disk", "thermal"}},
+		{name: "duplicates", input: "disk,disk", want: []string{"disk"}},
+		{name: "unknown", input: "power,gpu", wantErr: true},
+		{name: "only commas", input: ",,", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseDomains(tt.input)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("ParseDomains(%q) expected error, got %v", tt.input, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseDomains(%q) unexpected error: %v", tt.input, err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("ParseDomains(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("ParseDomains(%q)[%d] = %q, want %q", tt.input, i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestTasksMergesPowerAndThermal(t *testing.T) {
+	tasks := Tasks([]string{DomainThermal, DomainDisk, DomainPower})
+	if len(tasks) != 2 {
+		t.Fatalf("expected 2 tasks, got %d", len(tasks))
+	}
+	if tasks[0].Name != DomainPower {
+		t.Errorf("tasks[0].Name = %q, want %q", tasks[0].Name, DomainPower)
+	}
+	if tasks[1].Name != DomainDisk {
+		t.Errorf("tasks[1].Name = %q, want %q", tasks[1].Name, DomainDisk)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	interval := 5 * time.Minute
+	max := time.Hour
+
+	tests := []struct {
+		failures int
+		want     time.Duration
+	}{
+		{failures: 0, want: 5 * time.Minute},
+		{failures: 1, want: 5 * time.Minute},
+		{failures: 2, want: 10 * time.Minute},
+		{failures: 3, want: 20 * time.Minute},
+		{failures: 4, want: 40 * time.Minute},
+		{failures: 5, want: time.Hour},
+		{failures: 50, want: time.Hour},
+	}
+
+	for _, tt := range tests {
+		got := Backoff(interval, tt.failures, max)
+		if got != tt.want {
+			t.Errorf("Backoff(failures=%d) = %v, want %v", tt.failures, got, tt.want)
+		}
+	}
+}
+
+func TestJittered(t *testing.T) {
+	d := time.Minute
+	if got := Jittered(d, 0); got != d {
+		t.Errorf("Jittered with no jitter = %v, want %v", got, d)
+	}
+	for i := 0; i < 100; i++ {
+		got := Jittered(d, 10*time.Second)
+		if got < d || got >= d+10*time.Second {
+			t.Fatalf("Jittered = %v, want in [%v, %v)", got, d, d+10*time.Second)
+		}
+	}
+}
+
+func TestRunStopsOnCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	runs := 0
+	cfg := Config{
+		Interval: time.Millisecond,
+		Tasks: []Task{{Name: "test", Record: func() error {
+			runs++
+			if runs == 3 {
+				cancel()
+			}
+			return nil
+		}}},
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- Run(ctx, cfg, nil) }()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Run returned error: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run did not stop after cancel")
+	}
+
+	if runs != 3 {
+		t.Errorf("runs = %d, want 3", runs)
+	}
+}
+
+func TestRunBacksOffFailingTask(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var results []Result
+	cfg := Config{
+		Interval:   time.Millisecond,
+		MaxBackoff: time.Hour,
+		Tasks: []Task{{Name: "broken", Record: func() error {
+			return errors.New("tool failed")
+		}}},
+	}
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		cancel()
+	}()
+
+	if err := Run(ctx, cfg, func(r Result) { results = append(results, r) }); err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+
+	if len(results) == 0 {
+		t.Fatal("expected at least one result")
+	}
+	// Backoff doubles per failure, so within 50ms a 1ms task fails only a handful of times.
+	if len(results) > 8 {
+		t.Errorf("failing task ran %d times, expected backoff to limit retries", len(results))
+	}
+	last := results[len(results)-1]
+	if last.Success || last.Error == "" {
+		t.Errorf("expected failed result with error, got %+v", last)
+	}
+	if last.Failures != len(results) {
+		t.Errorf("Failures = %d, want %d", last.Failures, len(results))
+	}
+}
+
+func TestRunRejectsInvalidConfig(t *testing.T) {
+	if err := Run(context.Background(), Config{Interval: 0, Tasks: []Task{{Name: "x", Record: func() error { return nil }}}}, nil); err == nil {
+		t.Error("expected error for zero interval")
+	}
+	if err := Run(context.Background(), Config{Interval: time.Minute}, nil); err == nil {
+		t.Error("expected error for no tasks")
+	}
+}
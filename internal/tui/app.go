@@ -287,6 +287,13 @@ func (m Model) View() string {
 
 func renderBatteryGauge(s *power.Status) string {
 	var b strings.Builder
+	if !s.HasBattery {
+		b.WriteString(dimStyle.Render(fmt.Sprintf("  No battery (%s)", s.PowerSource)))
+		for _, src := range s.Sources {
+			b.WriteString(fmt.Sprintf("\n  %s: %d%% %s", src.Name, src.Percent, src.State))
+		}
+		return b.String()
+	}
 	pct := s.Percent
 	barLen := 20
 	filled := pct * barLen / 100